@@ -6,7 +6,17 @@ import (
 )
 
 func GetCommentAtField(fset *token.FileSet, astFile *ast.File, object *ast.Field) (ast.Comment, bool) {
-	line := fset.Position(object.Pos()).Line
-	comment, ok := GetCommentAtLine(fset, astFile, line)
-	return comment, ok
+	position := fset.Position(object.Pos())
+
+	// The file may hold the comments of several files, so the file name is
+	// compared as well as the line.
+	for _, commentGroup := range astFile.Comments {
+		for _, comment := range commentGroup.List {
+			commentPosition := fset.Position(comment.Pos())
+			if commentPosition.Filename == position.Filename && commentPosition.Line == position.Line {
+				return *comment, true
+			}
+		}
+	}
+	return ast.Comment{}, false
 }
@@ -0,0 +1,48 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// GetConstValues returns the values of the string constants declared with the
+// named type, in the order they are declared.
+func GetConstValues(astFile *ast.File, typeName string) []string {
+
+	var values []string
+
+	for _, decl := range astFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for _, value := range valueSpec.Values {
+				isTyped := valueSpec.Type != nil && types.ExprString(valueSpec.Type) == typeName
+
+				// Also accept conversions such as Direction("up").
+				if call, ok := value.(*ast.CallExpr); ok && len(call.Args) == 1 {
+					if types.ExprString(call.Fun) == typeName {
+						isTyped = true
+						value = call.Args[0]
+					}
+				}
+
+				lit, ok := value.(*ast.BasicLit)
+				if !ok || !isTyped || lit.Kind != token.STRING {
+					continue
+				}
+				if s, err := strconv.Unquote(lit.Value); err == nil {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values
+}
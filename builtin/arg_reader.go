@@ -0,0 +1,103 @@
+package builtin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Converts the values of the parameters in order, keeping the first error.
+type argReader struct {
+	values []string
+	params []Param
+	next   int
+	err    error
+}
+
+func (r *argReader) value() (string, string) {
+	value, name := r.values[r.next], r.params[r.next].Name
+	r.next++
+	return value, name
+}
+
+func (r *argReader) fail(name string, err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("invalid value of %s: %w", name, err)
+	}
+}
+
+func (r *argReader) int() int {
+	value, name := r.value()
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		r.fail(name, err)
+	}
+	return i
+}
+
+func (r *argReader) int64() int64 {
+	value, name := r.value()
+	i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		r.fail(name, err)
+	}
+	return i
+}
+
+func (r *argReader) float() float64 {
+	value, name := r.value()
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		r.fail(name, err)
+	}
+	return f
+}
+
+func (r *argReader) string() string {
+	value, _ := r.value()
+	return value
+}
+
+func (r *argReader) ints() []int {
+	value, name := r.value()
+	var ints []int
+	for _, s := range splitList(value) {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			r.fail(name, err)
+		}
+		ints = append(ints, i)
+	}
+	return ints
+}
+
+func (r *argReader) floats() []float64 {
+	value, name := r.value()
+	var floats []float64
+	for _, s := range splitList(value) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			r.fail(name, err)
+		}
+		floats = append(floats, f)
+	}
+	return floats
+}
+
+// Checks a condition on the value of the parameter.
+func (r *argReader) check(name string, ok bool, message string) {
+	if !ok {
+		r.fail(name, fmt.Errorf("%s", message))
+	}
+}
+
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t' || r == '\n'
+	})
+}
+
+// Returns a modulo b, which is never negative for a positive b.
+func mod(a, b int) int {
+	return (a%b + b) % b
+}
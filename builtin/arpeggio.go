@@ -0,0 +1,59 @@
+package builtin
+
+import "golang.org/x/exp/slices"
+
+var arpeggio = Definition{
+	Name:        "Arpeggio",
+	Description: "Arpeggiates the degrees of a chord over one or more octaves.",
+	Params: []Param{
+		{Name: "chord", Type: "xs:integer", List: true, Doc: "The degrees of the chord, e.g. \"0 2 4\"."},
+		{Name: "duration", Type: "xs:double", Doc: "The duration of each note in whole notes."},
+		{Name: "octaves", Type: "xs:positiveInteger", Doc: "How many octaves the arpeggio spans. An octave is 7 degrees."},
+		{Name: "pattern", Type: "xs:string", Doc: "The direction of the arpeggio: up, down or updown."},
+	},
+	new: func(r *argReader) Generator {
+		chord := r.ints()
+		duration := r.float()
+		octaves := r.int()
+		pattern := r.string()
+
+		r.check("chord", len(chord) > 0, "chord must not be empty")
+		r.check("duration", duration > 0, "duration must be positive")
+		r.check("octaves", octaves > 0, "octaves must be positive")
+
+		slices.Sort(chord)
+
+		var degrees []int
+		for octave := 0; octave < octaves; octave++ {
+			for _, degree := range chord {
+				degrees = append(degrees, degree+7*octave)
+			}
+		}
+
+		switch pattern {
+		case "up":
+		case "down":
+			degrees = reversed(degrees)
+		case "updown":
+			if len(degrees) > 2 {
+				down := reversed(degrees)
+				degrees = append(degrees, down[1:len(down)-1]...)
+			}
+		default:
+			r.check("pattern", false, "pattern must be up, down or updown")
+		}
+
+		return sequenceGenerator{
+			degrees:   degrees,
+			durations: []float64{duration},
+		}
+	},
+}
+
+func reversed(s []int) []int {
+	r := slices.Clone(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return r
+}
@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Version is the version in the tags of built-in generators, e.g.
+// "Sequence-builtin".
+const Version = "builtin"
+
+// PathPrefix marks the path of a built-in generator in the project XSD,
+// where compiled components have the path to their binary.
+const PathPrefix = "builtin:"
+
+// Generator is a generator that runs inside the interpreter.
+type Generator interface {
+	Generate(i int) (degree int, duration float64)
+}
+
+// Param is a parameter of a built-in generator.
+type Param struct {
+	Name string
+	// The XSD type of the value, or of its items if the value is a list.
+	Type string
+	// Whether the value is a list separated by spaces or commas.
+	List bool
+	Doc  string
+	// Whether the parameter may be left out, and the value it then has.
+	Optional bool
+	Default  string
+	// Whether the value is the path of a file, which is resolved against
+	// the project directory unless it is absolute.
+	File bool
+}
+
+// Definition describes a built-in generator.
+type Definition struct {
+	Name        string
+	Description string
+	// The parameters in the order their values are read in. The values are
+	// matched to the parameters by name, and optional parameters that are
+	// left out get their default.
+	Params []Param
+
+	new func(r *argReader) Generator
+}
+
+var definitions = []Definition{
+	sequence,
+	scale,
+	arpeggio,
+	euclid,
+	randomWalk,
+	markov,
+}
+
+// Definitions returns the definitions of all built-in generators.
+func Definitions() []Definition {
+	return definitions
+}
+
+// Lookup returns the definition of the built-in generator with the name and
+// version.
+func Lookup(name, version string) (Definition, bool) {
+	if version != Version {
+		return Definition{}, false
+	}
+	for _, definition := range definitions {
+		if definition.Name == name {
+			return definition, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Tag returns the tag of the generator in projects.
+func (d Definition) Tag() string {
+	return d.Name + "-" + Version
+}
+
+// Path returns the path of the generator in the project XSD.
+func (d Definition) Path() string {
+	return PathPrefix + d.Name
+}
+
+// IsPath reports whether the path is that of a built-in generator.
+func IsPath(path string) bool {
+	return strings.HasPrefix(path, PathPrefix)
+}
+
+// LookupPath returns the definition of the built-in generator at the path.
+func LookupPath(path string) (Definition, bool) {
+	if !IsPath(path) {
+		return Definition{}, false
+	}
+	return Lookup(strings.TrimPrefix(path, PathPrefix), Version)
+}
+
+// Args returns the values of the parameters in order, given the values by
+// name. Optional parameters that are left out get their default, and
+// relative file paths are resolved against dir, the project directory.
+func (d Definition) Args(values map[string]string, dir string) ([]string, error) {
+	var args []string
+
+	for _, param := range d.Params {
+		value, ok := values[param.Name]
+		if !ok {
+			if !param.Optional {
+				return nil, fmt.Errorf("%s is missing the parameter %s", d.Tag(), param.Name)
+			}
+			value = param.Default
+		}
+		if param.File && value != "" && !filepath.IsAbs(value) {
+			value = filepath.Join(dir, value)
+		}
+		args = append(args, value)
+	}
+
+	for name := range values {
+		if !slices.ContainsFunc(d.Params, func(param Param) bool { return param.Name == name }) {
+			return nil, fmt.Errorf("%s has no parameter %s", d.Tag(), name)
+		}
+	}
+
+	return args, nil
+}
+
+// New returns the built-in generator at the path, configured with the
+// values of its parameters.
+func New(path string, args []string) (Generator, error) {
+	definition, ok := LookupPath(path)
+	if !ok {
+		return nil, fmt.Errorf("unknown built-in generator: %s", path)
+	}
+
+	if len(args) != len(definition.Params) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", definition.Tag(), len(definition.Params), len(args))
+	}
+
+	r := &argReader{
+		values: args,
+		params: definition.Params,
+	}
+
+	generator := definition.new(r)
+
+	if r.err != nil {
+		return nil, fmt.Errorf("%s: %w", definition.Tag(), r.err)
+	}
+
+	return generator, nil
+}
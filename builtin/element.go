@@ -0,0 +1,46 @@
+package builtin
+
+import "github.com/beevik/etree"
+
+// Element returns the element of the generator in the project XSD.
+func (d Definition) Element() *etree.Element {
+	element := etree.NewElement("xs:element")
+	element.CreateAttr("name", d.Tag())
+
+	if d.Description != "" {
+		annotation := element.CreateElement("xs:annotation")
+		documentation := annotation.CreateElement("xs:documentation")
+		documentation.SetText(d.Description)
+	}
+
+	complexType := element.CreateElement("xs:complexType")
+
+	for _, param := range d.Params {
+		attribute := complexType.CreateElement("xs:attribute")
+		attribute.CreateAttr("name", param.Name)
+		if param.Optional {
+			attribute.CreateAttr("use", "optional")
+			attribute.CreateAttr("default", param.Default)
+		} else {
+			attribute.CreateAttr("use", "required")
+		}
+
+		if param.Doc != "" {
+			annotation := attribute.CreateElement("xs:annotation")
+			documentation := annotation.CreateElement("xs:documentation")
+			documentation.SetText(param.Doc)
+		}
+
+		simpleType := attribute.CreateElement("xs:simpleType")
+
+		if param.List {
+			list := simpleType.CreateElement("xs:list")
+			simpleType = list.CreateElement("xs:simpleType")
+		}
+
+		restriction := simpleType.CreateElement("xs:restriction")
+		restriction.CreateAttr("base", param.Type)
+	}
+
+	return element
+}
@@ -0,0 +1,61 @@
+package builtin
+
+var euclid = Definition{
+	Name:        "Euclid",
+	Description: "Plays a Euclidean rhythm, spreading a number of pulses as evenly as possible over a number of steps. Each note lasts until the next pulse.",
+	Params: []Param{
+		{Name: "degree", Type: "xs:integer", Doc: "The degree of every note."},
+		{Name: "duration", Type: "xs:double", Doc: "The duration of a step in whole notes."},
+		{Name: "pulses", Type: "xs:positiveInteger", Doc: "How many of the steps are pulses."},
+		{Name: "rotation", Type: "xs:integer", Doc: "How many steps to rotate the rhythm to the left."},
+		{Name: "steps", Type: "xs:positiveInteger", Doc: "The length of the rhythm in steps."},
+	},
+	new: func(r *argReader) Generator {
+		degree := r.int()
+		duration := r.float()
+		pulses := r.int()
+		rotation := r.int()
+		steps := r.int()
+
+		r.check("duration", duration > 0, "duration must be positive")
+		r.check("steps", steps > 0, "steps must be positive")
+		r.check("pulses", pulses > 0 && pulses <= steps, "pulses must be between 1 and steps")
+
+		if r.err != nil {
+			return nil
+		}
+
+		var onsets []int
+		for step := 0; step < steps; step++ {
+			if mod((step+rotation)*pulses, steps) < pulses {
+				onsets = append(onsets, step)
+			}
+		}
+
+		return euclidGenerator{
+			degree:   degree,
+			duration: duration,
+			steps:    steps,
+			onsets:   onsets,
+		}
+	},
+}
+
+type euclidGenerator struct {
+	degree   int
+	duration float64
+	steps    int
+	// The steps of the pulses within the rhythm.
+	onsets []int
+}
+
+func (g euclidGenerator) Generate(i int) (int, float64) {
+	j := mod(i, len(g.onsets))
+
+	next := g.steps + g.onsets[0]
+	if j+1 < len(g.onsets) {
+		next = g.onsets[j+1]
+	}
+
+	return g.degree, float64(next-g.onsets[j]) * g.duration
+}
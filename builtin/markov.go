@@ -0,0 +1,145 @@
+package builtin
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+var markov = Definition{
+	Name:        "Markov",
+	Description: "Generates variations of a phrase with a Markov chain over its degrees and durations. The phrase is given inline or read from a MIDI file. The output is the same for the same seed.",
+	Params: []Param{
+		{Name: "degrees", Type: "xs:integer", List: true, Doc: "The degrees of the phrase.", Optional: true},
+		{Name: "durations", Type: "xs:double", List: true, Doc: "The durations of the notes of the phrase in whole notes, repeated if there are fewer durations than degrees.", Optional: true},
+		{Name: "file", Type: "xs:string", File: true, Doc: "A MIDI file to read the phrase from instead, relative to the project directory. Its pitches are taken as degrees of the key of the file, or of C major if it has none.", Optional: true},
+		{Name: "order", Type: "xs:positiveInteger", Doc: "How many previous notes the next note depends on.", Optional: true, Default: "1"},
+		{Name: "seed", Type: "xs:long", Doc: "The seed of the chain.", Optional: true, Default: "0"},
+	},
+	new: func(r *argReader) Generator {
+		degrees := r.ints()
+		durations := r.floats()
+		file := r.string()
+		order := r.int()
+		seed := r.int64()
+
+		var phrase []markovState
+
+		if file != "" {
+			states, err := readMIDIPhrase(file)
+			if err != nil {
+				r.fail("file", err)
+			}
+			phrase = states
+		} else {
+			r.check("durations", len(durations) > 0, "durations must not be empty")
+			for i, degree := range degrees {
+				if len(durations) == 0 {
+					break
+				}
+				phrase = append(phrase, markovState{degree, durations[i%len(durations)]})
+			}
+		}
+
+		for _, state := range phrase {
+			r.check("durations", state.duration > 0, "durations must be positive")
+		}
+		r.check("order", order > 0, "order must be positive")
+		r.check("degrees", len(phrase) > order, "the phrase must be longer than the order")
+
+		if r.err != nil {
+			return nil
+		}
+
+		reversedPhrase := make([]markovState, len(phrase))
+		for i, state := range phrase {
+			reversedPhrase[len(phrase)-1-i] = state
+		}
+
+		return &markovGenerator{
+			order:    order,
+			seed:     seed,
+			forward:  newMarkovTable(phrase, order),
+			backward: newMarkovTable(reversedPhrase, order),
+			positive: phrase[:order],
+		}
+	},
+}
+
+type markovState struct {
+	degree   int
+	duration float64
+}
+
+// The states that follow each sequence of order states, as often as they
+// follow it in the phrase. The phrase wraps around, so every sequence has a
+// successor.
+type markovTable map[string][]markovState
+
+func newMarkovTable(phrase []markovState, order int) markovTable {
+	table := make(markovTable)
+
+	for i := range phrase {
+		previous := make([]markovState, order)
+		for j := range previous {
+			previous[j] = phrase[mod(i+j, len(phrase))]
+		}
+		key := markovKey(previous)
+		table[key] = append(table[key], phrase[mod(i+order, len(phrase))])
+	}
+
+	return table
+}
+
+func markovKey(states []markovState) string {
+	return fmt.Sprint(states)
+}
+
+type markovGenerator struct {
+	order    int
+	seed     int64
+	forward  markovTable
+	backward markovTable
+
+	// The states from index 0 onwards and from index -1 backwards, as far as
+	// they have been requested. The first states are those of the phrase.
+	positive []markovState
+	negative []markovState
+}
+
+func (g *markovGenerator) Generate(i int) (int, float64) {
+	state := g.state(i)
+	return state.degree, state.duration
+}
+
+func (g *markovGenerator) state(i int) markovState {
+	if i >= 0 {
+		for len(g.positive) <= i {
+			index := len(g.positive)
+			previous := g.positive[index-g.order:]
+			g.positive = append(g.positive, g.next(g.forward, previous, index))
+		}
+		return g.positive[i]
+	}
+
+	for len(g.negative) < -i {
+		index := -len(g.negative) - 1
+
+		// The states following the index, from the farthest to the nearest,
+		// which is their order in the reversed phrase.
+		following := make([]markovState, g.order)
+		for j := range following {
+			following[j] = g.state(index + g.order - j)
+		}
+
+		g.negative = append(g.negative, g.next(g.backward, following, index))
+	}
+	return g.negative[-i-1]
+}
+
+func (g *markovGenerator) next(table markovTable, previous []markovState, index int) markovState {
+	candidates := table[markovKey(previous)]
+
+	r := rand.New(rand.NewSource(g.seed + int64(index)))
+
+	return candidates[r.Intn(len(candidates))]
+}
@@ -0,0 +1,108 @@
+package builtin
+
+import (
+	"errors"
+
+	"gitlab.com/gomidi/midi/v2/smf"
+)
+
+var (
+	majorScale = []int{0, 2, 4, 5, 7, 9, 11}
+	minorScale = []int{0, 2, 3, 5, 7, 8, 10}
+)
+
+// Reads the notes of the first track of the MIDI file that has any. The
+// pitches are converted to degrees of the key of the file, counting from the
+// tonic nearest to middle C. Pitches outside the key are lowered to the
+// degree below. Rests and all but the first of simultaneous notes are left
+// out.
+func readMIDIPhrase(path string) ([]markovState, error) {
+	file, err := smf.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ticks, ok := file.TimeFormat.(smf.MetricTicks)
+	if !ok {
+		return nil, errors.New("MIDI files with SMPTE time are not supported")
+	}
+
+	ticksPerWholeNote := float64(ticks.Ticks4th()) * 4
+
+	tonic := 0
+	scale := majorScale
+
+	for _, track := range file.Tracks {
+		var phrase []markovState
+
+		type noteOn struct {
+			key  uint8
+			tick int64
+		}
+
+		var sounding *noteOn
+		var lastStart int64 = -1
+		var tick int64
+
+		for _, event := range track {
+			tick += int64(event.Delta)
+
+			var key smf.Key
+			if event.Message.GetMetaKey(&key) {
+				tonic = int(key.Key)
+				scale = majorScale
+				if !key.IsMajor {
+					scale = minorScale
+				}
+			}
+
+			var channel, pitch, velocity uint8
+
+			if event.Message.GetNoteStart(&channel, &pitch, &velocity) {
+				if sounding == nil && tick != lastStart {
+					sounding = &noteOn{pitch, tick}
+					lastStart = tick
+				}
+				continue
+			}
+
+			if event.Message.GetNoteEnd(&channel, &pitch) && sounding != nil && sounding.key == pitch {
+				phrase = append(phrase, markovState{
+					degree:   pitchToDegree(int(pitch), tonic, scale),
+					duration: float64(tick-sounding.tick) / ticksPerWholeNote,
+				})
+				sounding = nil
+			}
+		}
+
+		if len(phrase) > 0 {
+			return phrase, nil
+		}
+	}
+
+	return nil, errors.New("the MIDI file has no notes")
+}
+
+func pitchToDegree(pitch int, tonic int, scale []int) int {
+	// The tonic nearest to middle C.
+	base := 60 + tonic
+	if tonic > 6 {
+		base -= 12
+	}
+
+	offset := pitch - base
+	octave := offset / 12
+	if offset < 0 && offset%12 != 0 {
+		octave--
+	}
+	semitone := offset - octave*12
+
+	degree := 0
+	for i, step := range scale {
+		if step <= semitone {
+			degree = i
+		}
+	}
+
+	return octave*len(scale) + degree
+}
@@ -0,0 +1,89 @@
+package builtin
+
+import "math/rand"
+
+var randomWalk = Definition{
+	Name:        "RandomWalk",
+	Description: "Walks randomly up and down the scale within a range of degrees. The walk is the same for the same seed.",
+	Params: []Param{
+		{Name: "duration", Type: "xs:double", Doc: "The duration of each note in whole notes."},
+		{Name: "max", Type: "xs:integer", Doc: "The highest degree of the walk."},
+		{Name: "maxStep", Type: "xs:positiveInteger", Doc: "The most degrees the walk moves by at once."},
+		{Name: "min", Type: "xs:integer", Doc: "The lowest degree of the walk."},
+		{Name: "seed", Type: "xs:long", Doc: "The seed of the walk."},
+		{Name: "start", Type: "xs:integer", Doc: "The degree at index 0."},
+	},
+	new: func(r *argReader) Generator {
+		g := &randomWalkGenerator{
+			duration: r.float(),
+			max:      r.int(),
+			maxStep:  r.int(),
+			min:      r.int(),
+			seed:     r.int64(),
+		}
+		start := r.int()
+
+		r.check("duration", g.duration > 0, "duration must be positive")
+		r.check("maxStep", g.maxStep > 0, "maxStep must be positive")
+		r.check("max", g.min <= g.max, "max must not be below min")
+		r.check("start", g.min <= start && start <= g.max, "start must be between min and max")
+
+		g.positive = []int{start}
+
+		return g
+	},
+}
+
+type randomWalkGenerator struct {
+	duration float64
+	max      int
+	maxStep  int
+	min      int
+	seed     int64
+
+	// The degrees of the walk from index 0 onwards and from index -1
+	// backwards, as far as they have been requested.
+	positive []int
+	negative []int
+}
+
+func (g *randomWalkGenerator) Generate(i int) (int, float64) {
+	if i >= 0 {
+		for len(g.positive) <= i {
+			index := len(g.positive)
+			g.positive = append(g.positive, g.move(g.positive[index-1], index))
+		}
+		return g.positive[i], g.duration
+	}
+
+	for len(g.negative) < -i {
+		index := -len(g.negative) - 1
+		previous := g.positive[0]
+		if len(g.negative) > 0 {
+			previous = g.negative[len(g.negative)-1]
+		}
+		g.negative = append(g.negative, g.move(previous, index))
+	}
+	return g.negative[-i-1], g.duration
+}
+
+// Moves by the random step of the index, reflecting off the bounds.
+func (g *randomWalkGenerator) move(degree int, index int) int {
+	r := rand.New(rand.NewSource(g.seed + int64(index)))
+
+	degree += r.Intn(2*g.maxStep+1) - g.maxStep
+
+	for degree < g.min || degree > g.max {
+		if g.min == g.max {
+			return g.min
+		}
+		if degree > g.max {
+			degree = 2*g.max - degree
+		}
+		if degree < g.min {
+			degree = 2*g.min - degree
+		}
+	}
+
+	return degree
+}
@@ -0,0 +1,43 @@
+package builtin
+
+var scale = Definition{
+	Name:        "Scale",
+	Description: "Runs up or down the scale from one degree to another and starts over.",
+	Params: []Param{
+		{Name: "duration", Type: "xs:double", Doc: "The duration of each note in whole notes."},
+		{Name: "from", Type: "xs:integer", Doc: "The first degree of the run."},
+		{Name: "step", Type: "xs:positiveInteger", Doc: "How many degrees to move by."},
+		{Name: "to", Type: "xs:integer", Doc: "The degree the run ends at or before."},
+	},
+	new: func(r *argReader) Generator {
+		g := scaleGenerator{
+			duration: r.float(),
+			from:     r.int(),
+			step:     r.int(),
+			to:       r.int(),
+		}
+		r.check("duration", g.duration > 0, "duration must be positive")
+		r.check("step", g.step > 0, "step must be positive")
+		return g
+	},
+}
+
+type scaleGenerator struct {
+	duration float64
+	from     int
+	step     int
+	to       int
+}
+
+func (g scaleGenerator) Generate(i int) (int, float64) {
+	distance := g.to - g.from
+	direction := 1
+	if distance < 0 {
+		distance = -distance
+		direction = -1
+	}
+
+	length := distance/g.step + 1
+
+	return g.from + direction*g.step*mod(i, length), g.duration
+}
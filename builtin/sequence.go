@@ -0,0 +1,31 @@
+package builtin
+
+var sequence = Definition{
+	Name:        "Sequence",
+	Description: "Plays a fixed list of degrees with a fixed list of durations, repeating each list when it runs out.",
+	Params: []Param{
+		{Name: "degrees", Type: "xs:integer", List: true, Doc: "The degrees to play."},
+		{Name: "durations", Type: "xs:double", List: true, Doc: "The durations of the notes in whole notes."},
+	},
+	new: func(r *argReader) Generator {
+		g := sequenceGenerator{
+			degrees:   r.ints(),
+			durations: r.floats(),
+		}
+		r.check("degrees", len(g.degrees) > 0, "degrees must not be empty")
+		r.check("durations", len(g.durations) > 0, "durations must not be empty")
+		for _, duration := range g.durations {
+			r.check("durations", duration > 0, "durations must be positive")
+		}
+		return g
+	},
+}
+
+type sequenceGenerator struct {
+	degrees   []int
+	durations []float64
+}
+
+func (g sequenceGenerator) Generate(i int) (int, float64) {
+	return g.degrees[mod(i, len(g.degrees))], g.durations[mod(i, len(g.durations))]
+}
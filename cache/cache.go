@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// The name of the cache directory inside a project directory.
+const DirName = ".cache"
+
+const (
+	generationsDir   = "generations"
+	modificationsDir = "modifications"
+)
+
+// Cache stores the results of generators and modifiers on disk so that they
+// survive between sessions. Results are keyed by the hash of the component
+// binary together with its arguments, so recompiling a component
+// invalidates its results.
+type Cache struct {
+	dir string
+
+	mu     sync.Mutex
+	hashes map[string]binaryHash
+}
+
+type binaryHash struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// Open returns the cache of the project directory.
+func Open(projectDir string) *Cache {
+	return &Cache{
+		dir:    filepath.Join(projectDir, DirName),
+		hashes: make(map[string]binaryHash),
+	}
+}
+
+// Key returns the key of the results of the component at path when run
+// with args. Any extra values are included in the key as well.
+func (c *Cache) Key(path string, args []string, extra ...any) (string, error) {
+	hash, err := c.hashBinary(path)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(struct {
+		Hash  string   `json:"hash"`
+		Args  []string `json:"args"`
+		Extra []any    `json:"extra"`
+	}{hash, args, extra})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *Cache) hashBinary(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.hashes[path]
+	c.mu.Unlock()
+
+	if ok && cached.modTime.Equal(stat.ModTime()) && cached.size == stat.Size() {
+		return cached.hash, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	c.mu.Lock()
+	c.hashes[path] = binaryHash{stat.ModTime(), stat.Size(), hash}
+	c.mu.Unlock()
+
+	return hash, nil
+}
+
+func (c *Cache) load(kind, key string, v any) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, kind, key+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Cache) store(kind, key string, v any) error {
+	dir := filepath.Join(c.dir, kind)
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// partially written entry behind.
+	tmp, err := os.CreateTemp(dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, key+".json"))
+}
@@ -0,0 +1,37 @@
+package cache
+
+import "revolution/protocol"
+
+// Generation holds the results of a generator by index.
+type Generation struct {
+	// Whether the generator depends on the context of its notes. Results
+	// are then only valid for the context they were generated in.
+	IsContextual bool                  `json:"isContextual"`
+	Notes        map[int]GeneratedNote `json:"notes"`
+}
+
+// GeneratedNote is the result of a generator at a single index.
+type GeneratedNote struct {
+	Degree   int               `json:"degree"`
+	Duration float64           `json:"duration"`
+	Context  *protocol.Context `json:"context,omitempty"`
+}
+
+// LoadGeneration returns the cached results of a generator. The generation
+// is empty if nothing is cached under the key.
+func (c *Cache) LoadGeneration(key string) (Generation, error) {
+	var generation Generation
+
+	_, err := c.load(generationsDir, key, &generation)
+
+	if err != nil || generation.Notes == nil {
+		generation.Notes = make(map[int]GeneratedNote)
+	}
+
+	return generation, err
+}
+
+// StoreGeneration replaces the cached results of a generator.
+func (c *Cache) StoreGeneration(key string, generation Generation) error {
+	return c.store(generationsDir, key, generation)
+}
@@ -0,0 +1,15 @@
+package cache
+
+import "revolution/protocol"
+
+// LoadModification returns the cached output of a modifier.
+func (c *Cache) LoadModification(key string) ([]protocol.Note, bool, error) {
+	var output []protocol.Note
+	ok, err := c.load(modificationsDir, key, &output)
+	return output, ok, err
+}
+
+// StoreModification caches the output of a modifier.
+func (c *Cache) StoreModification(key string, output []protocol.Note) error {
+	return c.store(modificationsDir, key, output)
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stats describes the contents of a cache.
+type Stats struct {
+	Generations   int
+	Modifications int
+	// Total size of all entries in bytes.
+	Size int64
+}
+
+// GetStats counts the entries in the cache of the project directory.
+func GetStats(projectDir string) (Stats, error) {
+	var stats Stats
+
+	for kind, count := range map[string]*int{
+		generationsDir:   &stats.Generations,
+		modificationsDir: &stats.Modifications,
+	} {
+		entries, err := os.ReadDir(filepath.Join(projectDir, DirName, kind))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		for _, entry := range entries {
+			if filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return stats, err
+			}
+			*count++
+			stats.Size += info.Size()
+		}
+	}
+
+	return stats, nil
+}
+
+// Clear removes the cache of the project directory.
+func Clear(projectDir string) error {
+	return os.RemoveAll(filepath.Join(projectDir, DirName))
+}
@@ -0,0 +1,24 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the generation cache of the current project",
+	Long: `Generated notes and modification results are cached in the .cache
+directory of the project, so that the first render after a restart does not
+have to run every component again.
+
+Results are keyed by the hash of the component binary together with its
+arguments, so recompiling a component invalidates its cached results.`,
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,31 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"os"
+	"revolution/cache"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached generations and modifications",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		return cache.Clear(wd)
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+}
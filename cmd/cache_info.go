@@ -0,0 +1,41 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"revolution/cache"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheInfoCmd represents the cache info command
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Print the contents of the generation cache",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		stats, err := cache.GetStats(wd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Generations:  ", stats.Generations)
+		fmt.Println("Modifications:", stats.Modifications)
+		fmt.Printf("Size:          %.1f KiB\n", float64(stats.Size)/1024)
+
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheInfoCmd)
+}
@@ -32,7 +32,11 @@ to quickly create a Cobra application.`,
 
 		outDir := filepath.Join(resourceDir, "components")
 
-		if err := component.CompileComponent(outDir); err != nil {
+		keepTemp, _ := cmd.Flags().GetBool("keep-temp")
+
+		if err := component.CompileComponent(outDir, component.CompileOptions{
+			KeepTemp: keepTemp,
+		}); err != nil {
 			log.Fatalln(err)
 		}
 	},
@@ -50,4 +54,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// compileCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	compileCmd.Flags().Bool("keep-temp", false, "Keep the temporary directory and generated main file for debugging")
 }
@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// componentCmd represents the component command
+var componentCmd = &cobra.Command{
+	Use:   "component",
+	Short: "Manage the installed components",
+	Long: `Compiled components are installed in the components directory of the
+resource directory. These commands list, describe and remove them.
+
+Components are referred to by name, optionally followed by @ and a version
+or version constraint such as 1, 1.2, ^1.2 or latest. Without a version, the
+highest installed version is used.`,
+}
+
+// Splits a name@version argument. The version defaults to the latest one.
+func splitComponentArg(arg string) (string, string) {
+	name, version, ok := strings.Cut(arg, "@")
+	if !ok {
+		version = "latest"
+	}
+	return name, version
+}
+
+func init() {
+	rootCmd.AddCommand(componentCmd)
+}
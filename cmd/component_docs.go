@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"revolution/component"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// componentDocsCmd represents the component docs command
+var componentDocsCmd = &cobra.Command{
+	Use:   "docs [<name>[@<version>]]...",
+	Short: "Render Markdown documentation of installed components",
+	Long: `Render Markdown documentation of installed components from their
+metadata and the @doc annotations of their parameters. Without arguments,
+every installed component is documented.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		var entries []component.RegistryEntry
+
+		if len(args) == 0 {
+			componentDir, err := component.ComponentDir()
+			if err != nil {
+				return err
+			}
+
+			entries, err = component.ReadRegistry(componentDir)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, arg := range args {
+			name, version := splitComponentArg(arg)
+
+			entry, ok := component.LookupComponent(name, "", version)
+			if !ok {
+				return fmt.Errorf("component %s is not installed", arg)
+			}
+
+			entries = append(entries, entry)
+		}
+
+		var sections []string
+
+		for _, entry := range entries {
+			params, err := component.ReadParams(entry.Path)
+			if err != nil {
+				return fmt.Errorf("%s@%s: %w", entry.Name, entry.Version, err)
+			}
+
+			sections = append(sections, component.RenderDocs(entry, params))
+		}
+
+		docs := strings.Join(sections, "\n")
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			fmt.Print(docs)
+			return nil
+		}
+
+		return os.WriteFile(output, []byte(docs), 0666)
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentDocsCmd)
+
+	componentDocsCmd.Flags().StringP("output", "o", "", "Write the documentation to a file instead of printing it")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"revolution/component"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// componentInfoCmd represents the component info command
+var componentInfoCmd = &cobra.Command{
+	Use:   "info <name>[@<version>]",
+	Short: "Print the metadata and parameters of an installed component",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		name, version := splitComponentArg(args[0])
+
+		entry, ok := component.LookupComponent(name, "", version)
+		if !ok {
+			return fmt.Errorf("component %s is not installed", args[0])
+		}
+
+		params, err := component.ReadParams(entry.Path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Name:       ", entry.Name)
+		fmt.Println("Version:    ", entry.Version)
+		fmt.Println("Type:       ", entry.Type)
+		fmt.Println("Author:     ", entry.Author)
+		fmt.Println("Description:", strings.TrimSpace(entry.Description))
+		fmt.Println("Path:       ", entry.Path)
+		fmt.Println()
+
+		if len(params) == 0 {
+			fmt.Println("No parameters")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PARAMETER\tTYPE\tDEFAULT\tRESTRICTIONS\tDESCRIPTION")
+
+		for _, param := range params {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				param.Name,
+				param.TypeString(),
+				param.DefaultString(),
+				param.RestrictionString(),
+				param.Doc,
+			)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentInfoCmd)
+}
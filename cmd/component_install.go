@@ -0,0 +1,49 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"revolution/component"
+
+	"github.com/spf13/cobra"
+)
+
+// componentInstallCmd represents the component install command
+var componentInstallCmd = &cobra.Command{
+	Use:   "install <archive-or-dir>",
+	Short: "Install a component from a package or its source",
+	Long: `Install a component from an archive made with 'component pack', from a
+directory holding an unpacked one, or from the source directory of a
+component.
+
+The checksums of a package are verified before anything is installed. Its
+prebuilt binary for the current platform is used if it has one, and the
+component is built from source otherwise. An installed component of the
+same version is only replaced with --force.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		force, _ := cmd.Flags().GetBool("force")
+
+		entry, err := component.InstallComponent(args[0], force)
+		if errors.Is(err, component.ErrAlreadyInstalled) {
+			return fmt.Errorf("%w, use --force to replace it", err)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Installed %s %s %s\n", entry.Type, entry.Name, entry.Version)
+
+		return nil
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentInstallCmd)
+
+	componentInstallCmd.Flags().Bool("force", false, "Replace an installed component of the same version")
+}
@@ -0,0 +1,57 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"revolution/component"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// componentListCmd represents the component list command
+var componentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the installed components",
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		kind, _ := cmd.Flags().GetString("type")
+		if kind != "" && kind != "generator" && kind != "modifier" {
+			return fmt.Errorf("invalid type %s, expected generator or modifier", kind)
+		}
+
+		componentDir, err := component.ComponentDir()
+		if err != nil {
+			return err
+		}
+
+		entries, err := component.ReadRegistry(componentDir)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tTYPE\tDESCRIPTION")
+
+		for _, entry := range entries {
+			if kind != "" && entry.Type != kind {
+				continue
+			}
+			description, _, _ := strings.Cut(strings.TrimSpace(entry.Description), "\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Name, entry.Version, entry.Type, description)
+		}
+
+		return w.Flush()
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentListCmd)
+
+	componentListCmd.Flags().String("type", "", "Only list components of this type, generator or modifier")
+}
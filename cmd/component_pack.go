@@ -0,0 +1,60 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"revolution/component"
+
+	"github.com/spf13/cobra"
+)
+
+// componentPackCmd represents the component pack command
+var componentPackCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Pack the component in the current directory for sharing",
+	Long: `Pack the component in the current directory into a tar.gz archive that
+can be installed with 'component install'. The archive holds revocomp.yaml,
+the source of the component, binaries prebuilt for the platforms given with
+--platform and the checksums of all of them.
+
+Platforms without a prebuilt binary build the component from source on
+install, which requires Go.`,
+	Args: cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		info, err := component.ReadInfo(wd)
+		if err != nil {
+			return err
+		}
+
+		platforms, _ := cmd.Flags().GetStringSlice("platform")
+		output, _ := cmd.Flags().GetString("output")
+
+		if output == "" {
+			output = component.PackageFileName(info)
+		}
+
+		if err := component.PackComponent(wd, output, platforms); err != nil {
+			return err
+		}
+
+		fmt.Println("Packed", output)
+
+		return nil
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentPackCmd)
+
+	componentPackCmd.Flags().StringSlice("platform", nil, "Include a binary prebuilt for GOOS/GOARCH, e.g. linux/amd64")
+	componentPackCmd.Flags().StringP("output", "o", "", "The path of the archive, by default named after the component")
+}
@@ -0,0 +1,40 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"revolution/component"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// componentRemoveCmd represents the component remove command
+var componentRemoveCmd = &cobra.Command{
+	Use:   "remove <name>@<version>",
+	Short: "Remove an installed version of a component",
+	Long: `Remove an installed version of a component. The exact version must be
+given, so that a newer or older version is never removed by accident.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		name, version, ok := strings.Cut(args[0], "@")
+		if !ok {
+			return fmt.Errorf("invalid argument %s, expected <name>@<version>", args[0])
+		}
+
+		if err := component.RemoveComponent(name, version); err != nil {
+			return err
+		}
+
+		fmt.Println("Removed", args[0])
+
+		return nil
+	},
+}
+
+func init() {
+	componentCmd.AddCommand(componentRemoveCmd)
+}
@@ -4,10 +4,13 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"revolution/interpret"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -43,7 +46,11 @@ to quickly create a Cobra application.`,
 
 		/* Start interpreter */
 
-		err = interpret.Interpret(wd)
+		// Stop every component process before exiting on an interrupt.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = interpret.Interpret(ctx, wd)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,230 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"revolution/component"
+	"revolution/interpret"
+	"revolution/protocol"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test [name=value]...",
+	Short: "Build the component in the current directory and print its output",
+	Long: `Build the component in the current directory and run it with the given
+parameters over the same protocol the interpreter uses.
+
+Generators print their first notes. Modifiers print their output for a
+sample input, which is a C major scale unless --input names a JSON file
+with an array of notes.
+
+With --golden the output is compared to the given snapshot file, and the
+command fails if they differ. With --update the snapshot is written
+instead.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		info, err := component.ReadInfo(wd)
+		if err != nil {
+			return err
+		}
+
+		values, err := parseTestArgs(args)
+		if err != nil {
+			return err
+		}
+
+		keepTemp, _ := cmd.Flags().GetBool("keep-temp")
+		count, _ := cmd.Flags().GetInt("count")
+		inputPath, _ := cmd.Flags().GetString("input")
+		goldenPath, _ := cmd.Flags().GetString("golden")
+		update, _ := cmd.Flags().GetBool("update")
+
+		/* Build */
+
+		outDir, err := os.MkdirTemp("", "revolution_test_*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(outDir)
+
+		if err := component.CompileComponent(outDir, component.CompileOptions{
+			KeepTemp: keepTemp,
+		}); err != nil {
+			return err
+		}
+
+		builds, err := filepath.Glob(filepath.Join(outDir, "*.revocomp"))
+		if err != nil {
+			return err
+		}
+		if len(builds) != 1 {
+			return errors.New("failed to locate the built component")
+		}
+
+		/* Run */
+
+		var lines []string
+
+		switch info.Type {
+		case "generator":
+			notes, err := interpret.RunGenerator(builds[0], values, count)
+			if err != nil {
+				return err
+			}
+
+			lines = append(lines, "degree\tstart\tduration")
+			for _, note := range notes {
+				lines = append(lines, fmt.Sprintf("%d\t%g\t%g", note.Value, note.Start, note.Duration))
+			}
+		case "modifier":
+			input := sampleNotes()
+
+			if inputPath != "" {
+				data, err := os.ReadFile(inputPath)
+				if err != nil {
+					return err
+				}
+				input = nil
+				if err := json.Unmarshal(data, &input); err != nil {
+					return fmt.Errorf("%s: %w", inputPath, err)
+				}
+			}
+
+			notes, err := interpret.RunModifier(builds[0], values, input)
+			if err != nil {
+				return err
+			}
+
+			lines = append(lines, "value\tstart\tduration\tchannel\ttrack\tpause")
+			for _, note := range notes {
+				start := "-"
+				if note.Start != nil {
+					start = fmt.Sprint(*note.Start)
+				}
+				lines = append(lines, fmt.Sprintf("%d\t%s\t%g\t%d\t%d\t%t",
+					note.Value, start, note.Duration, note.Channel, note.Track, note.IsPause))
+			}
+		default:
+			return fmt.Errorf("component type is invalid")
+		}
+
+		output := strings.Join(lines, "\n") + "\n"
+
+		fmt.Print(output)
+
+		/* Snapshot */
+
+		if goldenPath == "" {
+			return nil
+		}
+
+		if update {
+			if err := os.MkdirAll(filepath.Dir(goldenPath), 0777); err != nil {
+				return err
+			}
+			if err := os.WriteFile(goldenPath, []byte(output), 0666); err != nil {
+				return err
+			}
+			fmt.Println("Updated", goldenPath)
+			return nil
+		}
+
+		golden, err := os.ReadFile(goldenPath)
+		if err != nil {
+			return err
+		}
+
+		if diff := diffLines(string(golden), output); diff != "" {
+			return fmt.Errorf("output differs from %s:\n%s", goldenPath, diff)
+		}
+
+		fmt.Println("Output matches", goldenPath)
+
+		return nil
+	},
+}
+
+// Converts name=value arguments to the --name=value arguments components
+// take. Parameters that are left out take their defaults.
+func parseTestArgs(args []string) ([]string, error) {
+	var values []string
+
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid argument %q, expected name=value", arg)
+		}
+		values = append(values, "--"+name+"="+value)
+	}
+
+	sort.Strings(values)
+
+	return values, nil
+}
+
+// An ascending C major scale of quarter notes.
+func sampleNotes() []protocol.Note {
+	var notes []protocol.Note
+
+	for i, value := range []int{60, 62, 64, 65, 67, 69, 71, 72} {
+		start := float64(i) * 0.25
+		notes = append(notes, protocol.Note{
+			Value:    value,
+			Start:    &start,
+			Duration: 0.25,
+		})
+	}
+
+	return notes
+}
+
+// Returns the lines that differ between want and got, or nothing if they
+// are equal.
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var diff strings.Builder
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&diff, "line %d:\n  want: %s\n  got:  %s\n", i+1, w, g)
+		}
+	}
+
+	return diff.String()
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+
+	testCmd.Flags().Int("count", 16, "The number of notes to generate")
+	testCmd.Flags().String("input", "", "A JSON file with the notes to pass to a modifier")
+	testCmd.Flags().String("golden", "", "A snapshot file to compare the output to")
+	testCmd.Flags().Bool("update", false, "Write the output to the snapshot file instead of comparing")
+	testCmd.Flags().Bool("keep-temp", false, "Keep the temporary directory and generated main file for debugging")
+}
@@ -57,6 +57,11 @@ func (g Generator) Generate(i int) (degree int, duration float64) {
 		logic here. Try to keep it fairly
 		lightweight to ensure performance.
 
+		To follow the key, meter and bar
+		structure of the composition,
+		declare GenerateWithContext(i int,
+		ctx Context) instead of Generate.
+
 	***************************************/
 
 	// Custom seed for this particular generation.
@@ -8,6 +8,9 @@ func NewModifier() Modifier {
 	return Modifier{}
 }
 
+// Declare ModifyWithContext(note Note, ctx Context) []Note instead of
+// Modify to receive notes with their start, key and meter, and to place
+// the returned notes at explicit starts. Finish then returns []Note.
 func (m Modifier) Modify(note revoutil.Note) []revoutil.Note {
 	return []revoutil.Note{note}
 }
@@ -0,0 +1,6 @@
+package component
+
+// Returns the name of the binary of a component in the components directory.
+func binaryFileName(info Info) string {
+	return fileBaseName(info) + ".revocomp"
+}
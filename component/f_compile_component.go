@@ -2,53 +2,65 @@ package component
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
-	"go/parser"
+	"go/ast"
 	"go/token"
+	"go/types"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"revolution/astutil"
 	"revolution/randutil"
 	"strings"
-	"text/template"
-	"time"
-
-	"go/format"
 
 	"github.com/beevik/etree"
-	"github.com/iancoleman/strcase"
 	"github.com/otiai10/copy"
-	"gopkg.in/yaml.v3"
 )
 
-func CompileComponent(outDir string) error {
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
+func CompileComponent(outDir string, options CompileOptions) error {
+	srcDir := options.SourceDir
+	if srcDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		srcDir = wd
 	}
 
-	if err := validateComponent(wd); err != nil {
+	pkg, err := validateComponent(srcDir)
+	if err != nil {
 		return err
 	}
 
 	// Create temporary directory
-	tempDirName := fmt.Sprintf("revolution_compilation_%d", time.Now().Unix())
-	tempDir := filepath.Join(os.TempDir(), tempDirName)
+	tempDir, err := os.MkdirTemp("", "revolution_compilation_*")
+	if err != nil {
+		return err
+	}
 
-	copy.Copy(wd, tempDir)
+	if options.KeepTemp {
+		fmt.Println("Keeping temporary directory:", tempDir)
+	} else {
+		defer os.RemoveAll(tempDir)
+	}
 
-	defer os.RemoveAll(tempDir)
+	if err := copy.Copy(srcDir, tempDir); err != nil {
+		return fmt.Errorf("failed to copy the component to %s: %w", tempDir, err)
+	}
 
 	// Read component info
-	xsdData, _ := os.ReadFile("revocomp.yaml")
-	var info Info
-	yaml.Unmarshal(xsdData, &info)
+	info, err := ReadInfo(srcDir)
+	if err != nil {
+		return err
+	}
 
-	srcCode, _ := os.ReadFile(filepath.Join(wd, "revocomp.go"))
+	// The declarations of every file of the package, as if they were in
+	// one file.
 	fset := token.NewFileSet()
-	astFile, _ := parser.ParseFile(fset, "", srcCode, parser.ParseComments)
+	_, astFile, err := parsePackage(fset, srcDir)
+	if err != nil {
+		return err
+	}
 
 	var funcName string
 	switch info.Type {
@@ -59,21 +71,17 @@ func CompileComponent(outDir string) error {
 	}
 
 	funcDecl := astutil.FindFuncDeclByName(astFile, funcName)
-	astutil.SortParameters(funcDecl)
 	params := funcDecl.Type.Params.List
 
-	attributes, err := generateAttributesFromFields(fset, astFile, params)
+	// The types of the parameters as the type checker resolved them, in the
+	// order of their names in params.
+	paramTypes := pkg.Scope().Lookup(funcName).Type().(*types.Signature).Params()
+
+	attributes, err := generateAttributesFromFields(fset, astFile, params, paramTypes)
 	if err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
-	if err := format.Node(&buf, fset, astFile); err != nil {
-		panic(err)
-	}
-
-	os.WriteFile(filepath.Join(tempDir, "revocomp.go"), buf.Bytes(), 0777)
-
 	element := etree.NewElement("xs:element")
 	elementName := info.Name + "-" + info.Version
 	element.CreateAttr("name", elementName)
@@ -87,7 +95,7 @@ func CompileComponent(outDir string) error {
 	doc.SetRoot(element)
 	doc.IndentTabs()
 
-	xsdData, err = doc.WriteToBytes()
+	xsdData, err := doc.WriteToBytes()
 	if err != nil {
 		return err
 	}
@@ -100,90 +108,110 @@ func CompileComponent(outDir string) error {
 
 	// Create main file
 
-	simpleParams := astutil.GetSimpleFields(params)
-
+	var mainParams []mainParam
 	var conversions []string
 
-	for i, param := range simpleParams {
-		convCode, err := generateStringConversion(
-			fmt.Sprintf("os.Args[%d]", i+1),
-			param.Name,
-			param.Type,
-		)
-		if err != nil {
-			return err
-		}
+	i := 0
+	for _, field := range params {
+		enum := getEnum(fset, astFile, field)
+		defaultValue := getDefault(fset, astFile, field)
+
+		for _, param := range astutil.GetSimpleFields([]*ast.Field{field}) {
+			t := paramType(paramTypes.At(i).Type())
+			i++
+
+			goType := types.TypeString(t, types.RelativeTo(pkg))
 
-		conversions = append(conversions, convCode)
+			underlying, ok := underlyingType(t)
+			if !ok {
+				return fmt.Errorf("type %s is not supported", goType)
+			}
+
+			convCode, err := generateStringConversion(
+				fmt.Sprintf("values[%q]", param.Name),
+				param.Name,
+				goType,
+				underlying,
+			)
+			if err != nil {
+				return err
+			}
+
+			conversions = append(conversions, convCode)
+
+			if len(enum) != 0 {
+				conversions = append(conversions, generateEnumCheck(param.Name, underlying, enum))
+			}
+
+			mainParam := mainParam{Name: param.Name}
+			if defaultValue != nil {
+				mainParam.Optional = true
+				mainParam.Default = *defaultValue
+			}
+			mainParams = append(mainParams, mainParam)
+		}
 	}
 
 	var paramNames []string
 
-	for _, param := range simpleParams {
+	for _, param := range mainParams {
 		paramNames = append(paramNames, param.Name)
 	}
 
-	data := struct {
-		XSDFileName, Conversions, NArgs, Args string
-	}{
+	hasContext := astutil.FindFuncDeclByName(astFile, "GenerateWithContext") != nil ||
+		astutil.FindFuncDeclByName(astFile, "ModifyWithContext") != nil
+
+	mainCode, err := renderMain(info.Type, mainData{
 		XSDFileName: xsdFileName,
 		Conversions: strings.Join(conversions, "; "),
 		Args:        strings.Join(paramNames, ", "),
-		NArgs:       fmt.Sprint(len(paramNames) + 1),
-	}
-
-	mainTmpl := template.New("mainTmpl")
-
-	var tmplData []byte
-
-	switch info.Type {
-	case "generator":
-		tmplData, err = files.ReadFile("boilerplate/generator/main.tmpl")
-		if err != nil {
-			return err
-		}
-	case "modifier":
-		tmplData, err = files.ReadFile("boilerplate/modifier/main.tmpl")
-		if err != nil {
-			return err
-		}
-		if astutil.FindFuncDeclByName(astFile, "Finish") == nil {
-			// If there is no Finish function, we remove the boilerplate for executing it.
-			tmplData = []byte(strings.ReplaceAll(
-				string(tmplData), `if input == "finish" { fmt.Println(modifier.Finish()); return }`, ``),
-			)
-		}
-	}
-
-	if _, err := mainTmpl.Parse(string(tmplData)); err != nil {
-		return err
-	}
-
-	var builder strings.Builder
-	if err := mainTmpl.Execute(&builder, data); err != nil {
+		Params:      mainParams,
+		HasFinish:   astutil.FindFuncDeclByName(astFile, "Finish") != nil,
+		HasContext:  hasContext,
+	})
+	if err != nil {
 		return err
 	}
 
 	mainFileName := randutil.GetRandomString(20) + ".go"
 	mainFilePath := filepath.Join(tempDir, mainFileName)
-	mainFileData := []byte(builder.String())
+	mainFileData := []byte(mainCode)
 	if err := os.WriteFile(mainFilePath, mainFileData, 0777); err != nil {
 		return err
 	}
 
 	buildName := randutil.GetRandomString(20)
 
+	if options.KeepTemp {
+		fmt.Println("Generated main file:", mainFilePath)
+	}
+
+	var stderr bytes.Buffer
+
 	cmd := exec.Command("go", "build", "-o", buildName)
 	cmd.Dir = tempDir
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if options.GOOS != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+options.GOOS)
+	}
+	if options.GOARCH != "" {
+		cmd.Env = append(cmd.Env, "GOARCH="+options.GOARCH)
+	}
 	if err := cmd.Run(); err != nil {
-		return errors.New("build failed")
+		output := mapBuildOutput(stderr.String(), tempDir, srcDir, mainFileName, options.KeepTemp)
+		return fmt.Errorf("build failed: %w\n%s", err, output)
 	}
 
 	src := filepath.Join(tempDir, buildName)
-	dstName := strcase.ToCamel(info.Name) + "@" + strings.ReplaceAll(info.Version, ".", "-") + ".revocomp"
-	dst := filepath.Join(outDir, dstName)
+	dst := filepath.Join(outDir, binaryFileName(info))
+
+	if err := installBinary(src, dst); err != nil {
+		return err
+	}
 
-	if err := copy.Copy(src, dst); err != nil {
+	// Index the new build.
+	if _, err := ReadRegistry(outDir); err != nil {
 		return err
 	}
 
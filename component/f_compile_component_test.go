@@ -0,0 +1,107 @@
+package component
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes a generator declaring the given types and the constructor, and
+// returns its directory.
+func writeTestComponent(t *testing.T, declarations, constructor string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":        "module fake\n\ngo 1.20\n",
+		"revocomp.yaml": "name: Fake\ntype: generator\nversion: 1.0.0\nauthor: Someone\ndescription: A fake generator.\n",
+		"types.go":      "package main\n\n" + declarations,
+		"generator.go": `package main
+
+type Generator struct{}
+
+` + constructor + `
+
+func (g Generator) Generate(i int) (degree int, duration float64) {
+	return 0, 1
+}
+`,
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestCompileComponentChecksEnums(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a component")
+	}
+
+	// The component is a module of its own.
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+
+	srcDir := writeTestComponent(t, `type Mode string
+
+const (
+	Major Mode = "major"
+	Minor Mode = "minor"
+)
+`, `func NewGenerator(
+	mode Mode,
+	step int, // @enum 1, 2
+	modes []Mode,
+) Generator {
+	return Generator{}
+}`)
+
+	outDir := t.TempDir()
+
+	if err := CompileComponent(outDir, CompileOptions{SourceDir: srcDir}); err != nil {
+		t.Fatalf("CompileComponent() error = %v", err)
+	}
+
+	path := filepath.Join(outDir, binaryFileName(Info{Name: "Fake", Version: "1.0.0"}))
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--mode=major", "--step=2", "--modes=minor,major"}},
+		{args: []string{"--mode=lydian", "--step=2", "--modes=minor"}, wantErr: "invalid value lydian for parameter mode, expected one of major, minor"},
+		{args: []string{"--mode=major", "--step=3", "--modes=minor"}, wantErr: "invalid value 3 for parameter step, expected one of 1, 2"},
+		{args: []string{"--mode=major", "--step=1", "--modes=minor,dorian"}, wantErr: "invalid value dorian for parameter modes, expected one of major, minor"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			cmd := exec.Command(path, test.args...)
+			cmd.Stdin = strings.NewReader(`{"type":"hello","version":2}` + "\n" + `{"type":"generate","index":0}` + "\n")
+
+			output, err := cmd.CombinedOutput()
+
+			if test.wantErr == "" {
+				if err != nil || !strings.Contains(string(output), `"type":"generated"`) {
+					t.Errorf("component failed with %v:\n%s", err, output)
+				}
+				return
+			}
+
+			if !strings.Contains(string(output), test.wantErr) {
+				t.Errorf("output = %s, want %q", output, test.wantErr)
+			}
+			// The hello is still answered, as for other invalid args.
+			if !strings.Contains(string(output), `"type":"hello"`) {
+				t.Errorf("output = %s, want a hello", output)
+			}
+		})
+	}
+}
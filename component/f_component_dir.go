@@ -0,0 +1,18 @@
+package component
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ComponentDir returns the directory compiled components are installed in.
+func ComponentDir() (string, error) {
+	resourceDir := viper.GetString("resource_directory")
+	if resourceDir == "" {
+		return "", errors.New("resource_directory is unspecified")
+	}
+
+	return filepath.Join(resourceDir, "components"), nil
+}
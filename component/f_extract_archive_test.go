@@ -0,0 +1,111 @@
+package component
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testArchiveEntry struct {
+	name     string
+	body     string
+	typeflag byte
+}
+
+func writeTestArchive(t *testing.T, path string, entries []testArchiveEntry) {
+	t.Helper()
+
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Mode:     0644,
+			Size:     int64(len(entry.body)),
+			Typeflag: entry.typeflag,
+		}
+		if entry.typeflag == tar.TypeSymlink {
+			header.Size = 0
+			header.Linkname = entry.body
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tarWriter.Write([]byte(entry.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "package.tar.gz")
+
+	writeTestArchive(t, archive, []testArchiveEntry{
+		{name: "revocomp.yaml", body: "name: Rand", typeflag: tar.TypeReg},
+		{name: "source/", typeflag: tar.TypeDir},
+		{name: "source/revocomp.go", body: "package main", typeflag: tar.TypeReg},
+	})
+
+	out := filepath.Join(dir, "out")
+	if err := extractArchive(archive, out); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "source", "revocomp.go"))
+	if err != nil || string(data) != "package main" {
+		t.Errorf("source/revocomp.go = %q, %v", data, err)
+	}
+}
+
+func TestExtractArchiveRejectsEntriesOutsideOfDir(t *testing.T) {
+	tests := []testArchiveEntry{
+		{name: "../evil", body: "evil", typeflag: tar.TypeReg},
+		{name: "source/../../evil", body: "evil", typeflag: tar.TypeReg},
+		{name: "..", typeflag: tar.TypeDir},
+		{name: "/evil", body: "evil", typeflag: tar.TypeReg},
+		{name: "link", body: "../evil", typeflag: tar.TypeSymlink},
+	}
+
+	for _, entry := range tests {
+		t.Run(entry.name, func(t *testing.T) {
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "package.tar.gz")
+
+			writeTestArchive(t, archive, []testArchiveEntry{entry})
+
+			out := filepath.Join(dir, "out")
+			if err := os.Mkdir(out, 0777); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractArchive(archive, out)
+			if err == nil || !strings.Contains(err.Error(), "archive entry") {
+				t.Fatalf("extractArchive() error = %v, want a rejected entry", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, "evil")); err == nil {
+				t.Error("a file was written outside of the package")
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package component
+
+import (
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Returns the name and version of a component as used in file names,
+// e.g. MyGenerator@1-2-0.
+func fileBaseName(info Info) string {
+	return strcase.ToCamel(info.Name) + "@" + strings.ReplaceAll(info.Version, ".", "-")
+}
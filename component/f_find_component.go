@@ -1,64 +1,62 @@
 package component
 
-import (
-	"errors"
-	"io/fs"
-	"os/exec"
-	"path/filepath"
+// FindComponent returns the path and version of the highest installed
+// version of a component that satisfies the version constraint.
+func FindComponent(name, kind, constraint string) (string, string, bool) {
+	entry, ok := LookupComponent(name, kind, constraint)
+	if !ok {
+		return "", "", false
+	}
 
-	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
-)
+	return entry.Path, entry.Version, true
+}
 
-func FindComponent(name, kind, version string) (string, bool) {
-	resourceDir := viper.GetString("resource_directory")
-	if resourceDir == "" {
-		return "", false
+// LookupComponent returns the registry entry of the highest installed
+// version of a component that satisfies the version constraint. Components
+// of any type match if kind is empty.
+func LookupComponent(name, kind, constraint string) (RegistryEntry, bool) {
+	componentDir, err := ComponentDir()
+	if err != nil {
+		return RegistryEntry{}, false
 	}
 
-	componentDir := filepath.Join(resourceDir, "components")
+	entries, err := ReadRegistry(componentDir)
+	if err != nil {
+		return RegistryEntry{}, false
+	}
 
-	var componentPath string
+	parsedConstraint, constraintErr := parseVersionConstraint(constraint)
 
-	filepath.Walk(componentDir, func(path string, info fs.FileInfo, err error) error {
+	var best *RegistryEntry
+	var bestVersion version
 
-		if err != nil {
-			return nil // Continue
+	for i, entry := range entries {
+		if entry.Name != name || (kind != "" && entry.Type != kind) {
+			continue
 		}
 
-		ext := filepath.Ext(info.Name())
-		if ext != ".revocomp" {
-			return nil // Continue
+		v, err := parseVersion(entry.Version)
+		if err != nil || constraintErr != nil {
+			// Without a semantic version or constraint, only exact
+			// matches count.
+			if entry.Version == constraint && best == nil {
+				best = &entries[i]
+			}
+			continue
 		}
-
-		cmd := exec.Command(path, "info")
-		output, err := cmd.Output()
-		if err != nil {
-			return nil // Continue
+		if !parsedConstraint.matches(v) {
+			continue
 		}
 
-		var componentInfo Info
-		if err := yaml.Unmarshal(output, &componentInfo); err != nil {
-			return nil // Continue
+		if best == nil || bestVersion.less(v) {
+			best = &entries[i]
+			bestVersion = v
 		}
+	}
 
-		isSameName := componentInfo.Name == name
-		isSameType := componentInfo.Type == kind
-		isSameVersion := componentInfo.Version == version
-
-		if !isSameName || !isSameType || !isSameVersion {
-			return nil // Continue
-		}
-
-		componentPath = path
-
-		return errors.New("") // Break
-
-	})
-
-	if componentPath == "" {
-		return "", false
+	if best == nil {
+		return RegistryEntry{}, false
 	}
 
-	return componentPath, true
+	return *best, true
 }
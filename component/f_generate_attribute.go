@@ -7,7 +7,7 @@ import (
 	"github.com/beevik/etree"
 )
 
-func generateAttribute(name, goType, doc string, restrictions map[string]string) (etree.Element, error) {
+func generateAttribute(name, goType, doc string, restrictions map[string]string, enum []string, defaultValue *string) (etree.Element, error) {
 
 	base, ok := typeMap[strings.TrimPrefix(goType, "[]")]
 	if !ok {
@@ -16,7 +16,12 @@ func generateAttribute(name, goType, doc string, restrictions map[string]string)
 
 	attribute := etree.NewElement("xs:attribute")
 	attribute.CreateAttr("name", name)
-	attribute.CreateAttr("use", "required")
+	if defaultValue != nil {
+		attribute.CreateAttr("use", "optional")
+		attribute.CreateAttr("default", *defaultValue)
+	} else {
+		attribute.CreateAttr("use", "required")
+	}
 
 	if doc != "" {
 		annotation := attribute.CreateElement("xs:annotation")
@@ -42,5 +47,10 @@ func generateAttribute(name, goType, doc string, restrictions map[string]string)
 		el.CreateAttr("value", value)
 	}
 
+	for _, value := range enum {
+		el := restriction.CreateElement("xs:enumeration")
+		el.CreateAttr("value", value)
+	}
+
 	return *attribute, nil
 }
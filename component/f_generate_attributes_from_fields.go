@@ -4,17 +4,28 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"revolution/astutil"
 	"revolution/strtags"
 	"strings"
 
 	"github.com/beevik/etree"
+	"golang.org/x/exp/slices"
 )
 
-func generateAttributesFromFields(fset *token.FileSet, astFile *ast.File, fields []*ast.Field) ([]etree.Element, error) {
+// The types of the parameters declared by fields are given in paramTypes, as
+// the type checker resolved them.
+func generateAttributesFromFields(fset *token.FileSet, astFile *ast.File, fields []*ast.Field, paramTypes *types.Tuple) ([]etree.Element, error) {
 	var attributes []etree.Element
 
+	i := 0
 	for _, field := range fields {
+		// The names of a field declare consecutive parameters of one type.
+		t := paramType(paramTypes.At(i).Type())
+		if len(field.Names) == 0 {
+			i++
+		}
+		i += len(field.Names)
 
 		restrictions := make(map[string]string)
 		var documentation string
@@ -34,23 +45,32 @@ func generateAttributesFromFields(fset *token.FileSet, astFile *ast.File, fields
 					}
 				}
 				if tag.Name == "doc" {
-					documentation = strings.Join(tag.Options, ",")
+					documentation = strings.TrimSpace(strings.Join(tag.Options, ","))
 				}
 			}
 		}
 
-		typeIdent, ok := field.Type.(*ast.Ident)
+		goType, ok := underlyingType(t)
 		if !ok {
-			continue
+			return nil, fmt.Errorf("type %s is not supported", types.ExprString(field.Type))
+		}
+
+		enum := getEnum(fset, astFile, field)
+		defaultValue := getDefault(fset, astFile, field)
+
+		if defaultValue != nil && len(enum) != 0 && !strings.HasPrefix(goType, "[]") && !slices.Contains(enum, *defaultValue) {
+			return nil, fmt.Errorf("default '%s' of parameter %s is not one of %s", *defaultValue, field.Names[0].Name, strings.Join(enum, ", "))
 		}
 
 		for _, nameIdent := range field.Names {
 
 			attribute, err := generateAttribute(
 				nameIdent.Name,
-				typeIdent.Name,
+				goType,
 				documentation,
 				restrictions,
+				enum,
+				defaultValue,
 			)
 			if err != nil {
 				return nil, err
@@ -0,0 +1,31 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generates code that fails on the args if the converted parameter, or any of
+// its elements, is not one of the allowed values.
+func generateEnumCheck(name, underlying string, values []string) string {
+
+	var cases []string
+	for _, value := range values {
+		if strings.TrimPrefix(underlying, "[]") == "string" {
+			cases = append(cases, fmt.Sprintf("%q", value))
+		} else {
+			cases = append(cases, value)
+		}
+	}
+
+	check := fmt.Sprintf(`switch v { case %s: default: failArgs(fmt.Errorf("invalid value %%v for parameter %%s, expected one of %%s", v, %q, %q)) }`,
+		strings.Join(cases, ", "),
+		name,
+		strings.Join(values, ", "),
+	)
+
+	if strings.HasPrefix(underlying, "[]") {
+		return fmt.Sprintf("for _, v := range %s { %s }", name, check)
+	}
+	return fmt.Sprintf("{ v := %s; %s }", name, check)
+}
@@ -7,15 +7,23 @@ import (
 	"text/template"
 )
 
-func generateStringConversion(src, dst, dstType string) (string, error) {
+// The underlying type is the supported type that dstType is declared as,
+// which differs from dstType for named types.
+func generateStringConversion(src, dst, dstType, underlying string) (string, error) {
 
 	base := strings.TrimPrefix(dstType, "[]")
 
-	if base == "string" {
+	if strings.TrimPrefix(underlying, "[]") == "string" {
 		if strings.HasPrefix(dstType, "[]") {
-			return fmt.Sprintf("%s := %s", dst, src), nil
+			if base == "string" {
+				return fmt.Sprintf("%s := strings.Split(%s, \",\")", dst, src), nil
+			}
+			return fmt.Sprintf("var %[1]s []%[2]s; for _, s := range strings.Split(%[3]s, \",\") { %[1]s = append(%[1]s, %[2]s(s)) }", dst, base, src), nil
 		} else {
-			return fmt.Sprintf("%s := strings.Split(%s, ',')", dst, src), nil
+			if base == "string" {
+				return fmt.Sprintf("%s := %s", dst, src), nil
+			}
+			return fmt.Sprintf("%s := %s(%s)", dst, base, src), nil
 		}
 	} else {
 
@@ -33,20 +41,20 @@ func generateStringConversion(src, dst, dstType string) (string, error) {
 			"float32": "strconv.ParseFloat(%s, 32)",
 			"float64": "strconv.ParseFloat(%s, 64)",
 			"bool":    "strconv.ParseBool(%s)",
-		}[base]
+		}[strings.TrimPrefix(underlying, "[]")]
 		if !ok {
 			return "", fmt.Errorf("conversion from string to %s is not implemented", base)
 		}
 
 		stringConvTempl, err := template.New("").Parse(
-			`{{.TmpVarName}}, err := {{.ConvMethod}}; if err != nil { log.Fatalln(err) }; {{.VarName}} := {{.Type}}({{.TmpVarName}})`,
+			`{{.TmpVarName}}, err := {{.ConvMethod}}; if err != nil { failArgs(fmt.Errorf("malformed value for parameter %s: %v", {{printf "%q" .Param}}, err)) }; {{.VarName}} := {{.Type}}({{.TmpVarName}})`,
 		)
 		if err != nil {
 			return "", err
 		}
 
 		sliceConvTempl, err := template.New("").Parse(
-			`var {{.VarName}} []{{.Type}}; for _, s := range strings.Split({{.Source}}, ',') { {{.LoopBody}} }`,
+			`var {{.VarName}} []{{.Type}}; for _, s := range strings.Split({{.Source}}, ",") { {{.LoopBody}} }`,
 		)
 		if err != nil {
 			return "", err
@@ -59,8 +67,9 @@ func generateStringConversion(src, dst, dstType string) (string, error) {
 			convMethod = fmt.Sprintf(convMethod, "s")
 
 			convData := struct {
-				VarName, TmpVarName, ConvMethod, Type string
+				VarName, TmpVarName, ConvMethod, Type, Param string
 			}{
+				Param:      dst,
 				VarName:    "val",
 				TmpVarName: tmpVarName,
 				ConvMethod: convMethod,
@@ -100,8 +109,9 @@ func generateStringConversion(src, dst, dstType string) (string, error) {
 			convMethod = fmt.Sprintf(convMethod, src)
 
 			data := struct {
-				VarName, TmpVarName, ConvMethod, Type string
+				VarName, TmpVarName, ConvMethod, Type, Param string
 			}{
+				Param:      dst,
 				VarName:    dst,
 				TmpVarName: tmpVarName,
 				ConvMethod: convMethod,
@@ -0,0 +1,43 @@
+package component
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// Returns a file declaring the types that the generated main file provides to
+// the component, such as Context, so that the component source can be type
+// checked on its own.
+func generatedDeclarations(fset *token.FileSet, kind string, hasContext bool) (*ast.File, error) {
+	mainCode, err := renderMain(kind, mainData{
+		XSDFileName: "component.xsd",
+		HasContext:  hasContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mainFile, err := parser.ParseFile(fset, "main.go", mainCode, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var decls []ast.Decl
+
+	for _, decl := range mainFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		// Only the exported types are meant for the component.
+		if typeSpec := genDecl.Specs[0].(*ast.TypeSpec); typeSpec.Name.IsExported() {
+			decls = append(decls, genDecl)
+		}
+	}
+
+	mainFile.Decls = decls
+	mainFile.Imports = nil
+
+	return mainFile, nil
+}
@@ -0,0 +1,25 @@
+package component
+
+import (
+	"go/ast"
+	"go/token"
+	"revolution/astutil"
+	"revolution/strtags"
+	"strings"
+)
+
+// Returns the value given by the @default tag of a parameter, or nil if the
+// parameter is required.
+func getDefault(fset *token.FileSet, astFile *ast.File, field *ast.Field) *string {
+
+	if comment, ok := astutil.GetCommentAtField(fset, astFile, field); ok {
+		for _, tag := range strtags.Extract(comment.Text) {
+			if tag.Name == "default" {
+				value := strings.TrimSpace(strings.Join(tag.Options, ","))
+				return &value
+			}
+		}
+	}
+
+	return nil
+}
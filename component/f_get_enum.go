@@ -0,0 +1,39 @@
+package component
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"revolution/astutil"
+	"revolution/strtags"
+	"strings"
+)
+
+// Returns the values allowed for a parameter, either listed by an @enum tag
+// or declared as constants of its named string type. Parameters that may
+// take any value return nothing.
+func getEnum(fset *token.FileSet, astFile *ast.File, field *ast.Field) []string {
+
+	if comment, ok := astutil.GetCommentAtField(fset, astFile, field); ok {
+		for _, tag := range strtags.Extract(comment.Text) {
+			if tag.Name != "enum" {
+				continue
+			}
+			var values []string
+			for _, option := range tag.Options {
+				if option = strings.TrimSpace(option); option != "" {
+					values = append(values, option)
+				}
+			}
+			return values
+		}
+	}
+
+	goType := strings.TrimPrefix(types.ExprString(field.Type), "[]")
+
+	if _, ok := typeMap[goType]; ok {
+		return nil
+	}
+
+	return astutil.GetConstValues(astFile, goType)
+}
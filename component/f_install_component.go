@@ -0,0 +1,141 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrAlreadyInstalled is returned when installing a version of a component
+// that is already installed, without replacing it.
+var ErrAlreadyInstalled = errors.New("already installed")
+
+// InstallComponent installs a component from a package archive, or from a
+// directory holding an unpacked package or the source of a component. A
+// binary prebuilt for the current platform is used if the package has one,
+// and the component is built from source otherwise. An installed binary of
+// the same version is only replaced if replace is set.
+func InstallComponent(src string, replace bool) (RegistryEntry, error) {
+	componentDir, err := ComponentDir()
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	stat, err := os.Stat(src)
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	pkgDir := src
+
+	if stat.IsDir() {
+		_, err := os.Stat(filepath.Join(src, packageChecksumsFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			// Not a package, but the source of a component.
+			info, err := ReadInfo(src)
+			if err != nil {
+				return RegistryEntry{}, err
+			}
+			if err := checkNotInstalled(componentDir, info, replace); err != nil {
+				return RegistryEntry{}, err
+			}
+			if err := CompileComponent(componentDir, CompileOptions{SourceDir: src}); err != nil {
+				return RegistryEntry{}, err
+			}
+			return registeredComponent(componentDir, info)
+		}
+		if err != nil {
+			return RegistryEntry{}, err
+		}
+	} else {
+		tempDir, err := os.MkdirTemp("", "revolution_install_*")
+		if err != nil {
+			return RegistryEntry{}, err
+		}
+		defer os.RemoveAll(tempDir)
+
+		if err := extractArchive(src, tempDir); err != nil {
+			return RegistryEntry{}, err
+		}
+		pkgDir = tempDir
+	}
+
+	if err := verifyChecksums(pkgDir); err != nil {
+		return RegistryEntry{}, err
+	}
+
+	info, err := ReadInfo(pkgDir)
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	if err := checkNotInstalled(componentDir, info, replace); err != nil {
+		return RegistryEntry{}, err
+	}
+
+	binPath := filepath.Join(pkgDir, packageBinDir, runtime.GOOS+"_"+runtime.GOARCH+".revocomp")
+
+	if _, err := os.Stat(binPath); err == nil {
+		if err := installBinary(binPath, filepath.Join(componentDir, binaryFileName(info))); err != nil {
+			return RegistryEntry{}, err
+		}
+	} else {
+		fmt.Println("No binary for", runtime.GOOS+"/"+runtime.GOARCH, "in the package, building from source")
+
+		if err := CompileComponent(componentDir, CompileOptions{
+			SourceDir: filepath.Join(pkgDir, packageSourceDir),
+		}); err != nil {
+			return RegistryEntry{}, err
+		}
+	}
+
+	return registeredComponent(componentDir, info)
+}
+
+// Returns ErrAlreadyInstalled if the version of the component is installed,
+// unless it may be replaced.
+func checkNotInstalled(componentDir string, info Info, replace bool) error {
+	if replace {
+		return nil
+	}
+
+	_, err := os.Stat(filepath.Join(componentDir, binaryFileName(info)))
+	if err == nil {
+		return fmt.Errorf("%s %s %s is %w", info.Type, info.Name, info.Version, ErrAlreadyInstalled)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Returns the registry entry of a newly installed component, after checking
+// that the binary is the component the package describes. A binary that is
+// not is removed again.
+func registeredComponent(componentDir string, info Info) (RegistryEntry, error) {
+	path := filepath.Join(componentDir, binaryFileName(info))
+
+	entries, err := ReadRegistry(componentDir)
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	for _, entry := range entries {
+		if entry.Path != path {
+			continue
+		}
+		if entry.Name != info.Name || entry.Type != info.Type || entry.Version != info.Version {
+			break
+		}
+		return entry, nil
+	}
+
+	os.Remove(path)
+	ReadRegistry(componentDir)
+
+	return RegistryEntry{}, fmt.Errorf("the installed binary is not %s %s %s", info.Type, info.Name, info.Version)
+}
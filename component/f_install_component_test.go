@@ -0,0 +1,57 @@
+package component
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestInstallComponentReplacesOnlyWhenAsked(t *testing.T) {
+	resourceDir := t.TempDir()
+
+	previous := viper.Get("resource_directory")
+	viper.Set("resource_directory", resourceDir)
+	t.Cleanup(func() { viper.Set("resource_directory", previous) })
+
+	info := "name: Rand\ntype: generator\nversion: 1.0.0"
+
+	pkgDir := t.TempDir()
+	binName := packageBinDir + "/" + runtime.GOOS + "_" + runtime.GOARCH + ".revocomp"
+
+	writeTestPackage(t, pkgDir, map[string]string{
+		"revocomp.yaml": info,
+		binName:         fakeBinary(t, info),
+	}, nil)
+	if err := os.Chmod(filepath.Join(pkgDir, filepath.FromSlash(binName)), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := InstallComponent(pkgDir, false)
+	if err != nil {
+		t.Fatalf("InstallComponent() error = %v", err)
+	}
+	if entry.Name != "Rand" || entry.Version != "1.0.0" {
+		t.Errorf("InstallComponent() = %+v", entry)
+	}
+
+	if _, err := InstallComponent(pkgDir, false); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Fatalf("InstallComponent() error = %v, want %v", err, ErrAlreadyInstalled)
+	}
+
+	if _, err := InstallComponent(pkgDir, true); err != nil {
+		t.Fatalf("InstallComponent() with replace error = %v", err)
+	}
+
+	// Only the binary and the index are left in the components directory.
+	files, err := os.ReadDir(filepath.Join(resourceDir, "components"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("components directory holds %d files, want 2", len(files))
+	}
+}
@@ -0,0 +1,37 @@
+package component
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Replaces the paths in the temporary directory in the output of go build
+// with the paths of the files they are copied from. The generated main file
+// has no such file, so it is named as such unless the temporary directory is
+// kept.
+func mapBuildOutput(output, tempDir, wd, mainFileName string, keepTemp bool) string {
+	mainFileReplacement := "(generated main)"
+	if keepTemp {
+		mainFileReplacement = filepath.Join(tempDir, mainFileName)
+	}
+
+	// The main file is named last, as its path in the kept temporary
+	// directory must not be mapped to the working directory.
+	const mainFilePlaceholder = "\x00main\x00"
+
+	output = strings.ReplaceAll(output, filepath.Join(tempDir, mainFileName), mainFilePlaceholder)
+	output = strings.ReplaceAll(output, "./"+mainFileName, mainFilePlaceholder)
+	output = strings.ReplaceAll(output, tempDir, wd)
+
+	var lines []string
+
+	for _, line := range strings.Split(output, "\n") {
+		// Files in the package directory are printed relative to it.
+		if strings.HasPrefix(line, "./") {
+			line = wd + string(filepath.Separator) + strings.TrimPrefix(line, "./")
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.ReplaceAll(strings.Join(lines, "\n"), mainFilePlaceholder, mainFileReplacement)
+}
@@ -0,0 +1,51 @@
+package component
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMapBuildOutput(t *testing.T) {
+	tempDir := filepath.Join("tmp", "revolution_compilation_1")
+	wd := filepath.Join("src", "Rand")
+	mainFileName := "abc.go"
+
+	output := "# fake\n" +
+		"./abc.go:10:2: undefined: log\n" +
+		"./revocomp.go:5:1: missing return\n" +
+		filepath.Join(tempDir, "types.go") + ":3:6: Mode redeclared\n" +
+		filepath.Join(tempDir, mainFileName) + ":12:1: syntax error"
+
+	tests := []struct {
+		name     string
+		keepTemp bool
+		want     string
+	}{
+		{
+			name: "removed",
+			want: "# fake\n" +
+				"(generated main):10:2: undefined: log\n" +
+				filepath.Join(wd, "revocomp.go") + ":5:1: missing return\n" +
+				filepath.Join(wd, "types.go") + ":3:6: Mode redeclared\n" +
+				"(generated main):12:1: syntax error",
+		},
+		{
+			name:     "kept",
+			keepTemp: true,
+			want: "# fake\n" +
+				filepath.Join(tempDir, mainFileName) + ":10:2: undefined: log\n" +
+				filepath.Join(wd, "revocomp.go") + ":5:1: missing return\n" +
+				filepath.Join(wd, "types.go") + ":3:6: Mode redeclared\n" +
+				filepath.Join(tempDir, mainFileName) + ":12:1: syntax error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := mapBuildOutput(output, tempDir, wd, mainFileName, test.keepTemp)
+			if got != test.want {
+				t.Errorf("mapBuildOutput() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package component
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The layout of a component package. The archive holds revocomp.yaml, the
+// source of the component in the source directory, binaries prebuilt for
+// some platforms in the bin directory, named GOOS_GOARCH.revocomp, and the
+// SHA-256 of all of them in the checksums file, in the format of sha256sum.
+const (
+	packageSourceDir     = "source"
+	packageBinDir        = "bin"
+	packageChecksumsFile = "checksums.txt"
+)
+
+// PackComponent packs the component in srcDir into a tar.gz archive at
+// outPath, together with binaries prebuilt for the given platforms, written
+// as GOOS/GOARCH.
+func PackComponent(srcDir, outPath string, platforms []string) error {
+	if _, err := validateComponent(srcDir); err != nil {
+		return err
+	}
+
+	info, err := ReadInfo(srcDir)
+	if err != nil {
+		return err
+	}
+
+	absOutPath, err := filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+
+	// Files of the archive by their name in it.
+	files := map[string]string{
+		"revocomp.yaml": filepath.Join(srcDir, "revocomp.yaml"),
+	}
+
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Leave out hidden directories such as .git, and earlier builds.
+		if d.IsDir() {
+			if path != srcDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(path) == ".revocomp" {
+			return nil
+		}
+		if absPath, err := filepath.Abs(path); err == nil && absPath == absOutPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		files[packageSourceDir+"/"+filepath.ToSlash(rel)] = path
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "revolution_pack_*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, platform := range platforms {
+		goos, goarch, ok := strings.Cut(platform, "/")
+		if !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("invalid platform %s, expected GOOS/GOARCH", platform)
+		}
+
+		binDir := filepath.Join(tempDir, goos+"_"+goarch)
+
+		if err := CompileComponent(binDir, CompileOptions{
+			SourceDir: srcDir,
+			GOOS:      goos,
+			GOARCH:    goarch,
+		}); err != nil {
+			return fmt.Errorf("%s: %w", platform, err)
+		}
+
+		files[packageBinDir+"/"+goos+"_"+goarch+".revocomp"] = filepath.Join(binDir, binaryFileName(info))
+	}
+
+	return writeArchive(outPath, files)
+}
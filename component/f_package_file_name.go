@@ -0,0 +1,7 @@
+package component
+
+// PackageFileName returns the default name of the package archive of a
+// component.
+func PackageFileName(info Info) string {
+	return fileBaseName(info) + ".tar.gz"
+}
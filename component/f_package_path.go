@@ -0,0 +1,19 @@
+package component
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Returns the path of the file with the slash separated name in the package
+// in dir. Names that lead outside of dir are rejected.
+func packagePath(dir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" ||
+		strings.HasPrefix(clean, string(filepath.Separator)) ||
+		clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of the package", name)
+	}
+	return filepath.Join(dir, clean), nil
+}
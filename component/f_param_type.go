@@ -0,0 +1,25 @@
+package component
+
+import (
+	"go/types"
+)
+
+// Returns the type of a parameter as the generated main file converts to it.
+// Aliases of slices are replaced by the slices, which are converted element
+// by element. Other aliases are kept, as the generated main file can refer to
+// them by name like named types.
+func paramType(t types.Type) types.Type {
+	switch t := t.(type) {
+	case *types.Basic, *types.Named:
+		return t
+	case *types.Slice:
+		return types.NewSlice(paramType(t.Elem()))
+	}
+
+	// Newer versions of go/types keep aliases, which is all that is left.
+	if slice, ok := t.Underlying().(*types.Slice); ok {
+		return types.NewSlice(paramType(slice.Elem()))
+	}
+
+	return t
+}
@@ -0,0 +1,55 @@
+package component
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestParamTypesFromOtherFiles(t *testing.T) {
+	dir := writeTestComponent(t, `type Seed = int64
+
+type Degree int
+
+type Degrees = []Degree
+
+type DegreeAlias = Degree
+
+type Name string
+`, `func NewGenerator(seed Seed, degree Degree, degrees Degrees, alias DegreeAlias, names []Name) Generator {
+	return Generator{}
+}`)
+
+	pkg, err := validateComponent(dir)
+	if err != nil {
+		t.Fatalf("validateComponent() error = %v", err)
+	}
+
+	params := pkg.Scope().Lookup("NewGenerator").Type().(*types.Signature).Params()
+
+	tests := []struct {
+		goType, underlying string
+	}{
+		{"int64", "int64"},
+		{"Degree", "int"},
+		{"[]Degree", "[]int"},
+		{"Degree", "int"},
+		{"[]Name", "[]string"},
+	}
+
+	for i, test := range tests {
+		param := params.At(i)
+		typ := paramType(param.Type())
+
+		goType := types.TypeString(typ, types.RelativeTo(pkg))
+		underlying, ok := underlyingType(typ)
+
+		// Newer versions of go/types keep the name of the alias, which the
+		// generated main file can convert to as well.
+		if goType != test.goType && goType != types.TypeString(param.Type(), types.RelativeTo(pkg)) {
+			t.Errorf("type of %s = %s, want %s", param.Name(), goType, test.goType)
+		}
+		if !ok || underlying != test.underlying {
+			t.Errorf("underlying type of %s = %s, want %s", param.Name(), underlying, test.underlying)
+		}
+	}
+}
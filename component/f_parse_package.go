@@ -0,0 +1,38 @@
+package component
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+)
+
+// Parses the Go files of the package in dir that are built on the current
+// platform. Besides the files themselves, a single file holding all of their
+// declarations and comments is returned, for looking up declarations
+// regardless of the file they are in.
+func parsePackage(fset *token.FileSet, dir string) ([]*ast.File, *ast.File, error) {
+	buildPkg, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := &ast.File{Name: ast.NewIdent(buildPkg.Name)}
+
+	var astFiles []*ast.File
+
+	for _, name := range buildPkg.GoFiles {
+		astFile, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		astFiles = append(astFiles, astFile)
+
+		merged.Decls = append(merged.Decls, astFile.Decls...)
+		merged.Comments = append(merged.Comments, astFile.Comments...)
+	}
+
+	return astFiles, merged, nil
+}
@@ -0,0 +1,25 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadInfo reads the revocomp.yaml of the component in dir.
+func ReadInfo(dir string) (Info, error) {
+	var info Info
+
+	data, err := os.ReadFile(filepath.Join(dir, "revocomp.yaml"))
+	if err != nil {
+		return info, err
+	}
+
+	if err := yaml.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("revocomp.yaml: %w", err)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,56 @@
+package component
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+// ReadParams returns the parameters of the compiled component at path.
+func ReadParams(path string) ([]Param, error) {
+	output, err := exec.Command(path, "xsd").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get XSD for component: %w", err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(output); err != nil {
+		return nil, fmt.Errorf("failed to parse XSD for component: %w", err)
+	}
+
+	var params []Param
+
+	for _, attribute := range doc.FindElements("//xs:attribute") {
+		param := Param{
+			Name:         attribute.SelectAttrValue("name", ""),
+			Optional:     attribute.SelectAttrValue("use", "") == "optional",
+			Default:      attribute.SelectAttrValue("default", ""),
+			Restrictions: make(map[string]string),
+		}
+
+		if documentation := attribute.FindElement("xs:annotation/xs:documentation"); documentation != nil {
+			param.Doc = strings.TrimSpace(documentation.Text())
+		}
+
+		param.List = attribute.FindElement("xs:simpleType/xs:list") != nil
+
+		if restriction := attribute.FindElement(".//xs:restriction"); restriction != nil {
+			param.Type = strings.TrimPrefix(restriction.SelectAttrValue("base", ""), "xs:")
+
+			for _, el := range restriction.ChildElements() {
+				value := el.SelectAttrValue("value", "")
+				if el.Tag == "enumeration" {
+					param.Enum = append(param.Enum, value)
+				} else {
+					param.Restrictions[el.Tag] = value
+				}
+			}
+		}
+
+		params = append(params, param)
+	}
+
+	return params, nil
+}
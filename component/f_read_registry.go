@@ -0,0 +1,185 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The name of the registry index inside the components directory.
+const registryFileName = "index.json"
+
+// ReadRegistry returns the components in the components directory. The
+// entries are kept in an index, which is brought up to date with the
+// binaries on disk first. Only binaries that were added or changed since
+// the last read are hashed, and only those with a new hash are asked for
+// their info.
+func ReadRegistry(componentDir string) ([]RegistryEntry, error) {
+	indexed := make(map[string]RegistryEntry)
+	hashed := make(map[string]RegistryEntry)
+	isChanged := false
+
+	data, err := os.ReadFile(filepath.Join(componentDir, registryFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		var index registryIndex
+		// An unreadable or outdated index is rebuilt from scratch.
+		if json.Unmarshal(data, &index) == nil && index.Version == registryVersion {
+			for _, entry := range index.Entries {
+				indexed[entry.Path] = entry
+				hashed[entry.Hash] = entry
+			}
+		} else {
+			isChanged = true
+		}
+	}
+
+	var entries []RegistryEntry
+
+	err = filepath.WalkDir(componentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Continue
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".revocomp" {
+			return nil // Continue
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil // Continue
+		}
+
+		entry, ok := indexed[path]
+		if ok && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+			entries = append(entries, entry)
+			return nil
+		}
+
+		isChanged = true
+
+		hash, err := hashFile(path)
+		if err != nil {
+			return nil // Continue
+		}
+
+		entry, ok = hashed[hash]
+		if !ok {
+			componentInfo, err := readBinaryInfo(path)
+			if err != nil {
+				return nil // Continue
+			}
+			entry = RegistryEntry{
+				Name:        componentInfo.Name,
+				Type:        componentInfo.Type,
+				Version:     componentInfo.Version,
+				Author:      componentInfo.Author,
+				Description: componentInfo.Description,
+				Hash:        hash,
+			}
+		}
+
+		entry.Path = path
+		entry.ModTime = info.ModTime()
+		entry.Size = info.Size()
+
+		entries = append(entries, entry)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) != len(indexed) {
+		isChanged = true
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	if isChanged {
+		if err := writeRegistry(componentDir, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+func writeRegistry(componentDir string, entries []RegistryEntry) error {
+	if err := os.MkdirAll(componentDir, 0777); err != nil {
+		return err
+	}
+
+	index := registryIndex{
+		Version: registryVersion,
+		Entries: entries,
+	}
+
+	data, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a reader never sees a
+	// partially written index.
+	tmp, err := os.CreateTemp(componentDir, registryFileName+"-*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(componentDir, registryFileName))
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func readBinaryInfo(path string) (Info, error) {
+	output, err := exec.Command(path, "info").Output()
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	if err := yaml.Unmarshal(output, &info); err != nil {
+		return Info{}, err
+	}
+
+	return info, nil
+}
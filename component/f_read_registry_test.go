@@ -0,0 +1,116 @@
+package component
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Returns a script that answers the info command like a compiled component.
+func fakeBinary(t *testing.T, info string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	return "#!/bin/sh\ncat <<'EOF'\n" + info + "\nEOF\n"
+}
+
+func writeFakeBinary(t *testing.T, path, info string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(fakeBinary(t, info)), 0777); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRegistryRebuildsOutdatedIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Rand@1-0-0.revocomp")
+
+	writeFakeBinary(t, path, "name: Rand\ntype: generator\nversion: 1.0.0\nauthor: Someone\ndescription: Random notes.")
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An index from before the author and description were indexed, which
+	// matches the binary on disk.
+	outdated, err := json.Marshal([]RegistryEntry{{
+		Name:    "Rand",
+		Type:    "generator",
+		Version: "1.0.0",
+		Path:    path,
+		ModTime: stat.ModTime(),
+		Size:    stat.Size(),
+		Hash:    "outdated",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, registryFileName), outdated, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Author != "Someone" || entries[0].Description != "Random notes." {
+		t.Fatalf("ReadRegistry() = %+v, want the info of the binary", entries)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, registryFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var index registryIndex
+	if err := json.Unmarshal(data, &index); err != nil || index.Version != registryVersion {
+		t.Errorf("index was not rewritten in version %d: %s", registryVersion, data)
+	}
+}
+
+func TestReadRegistryReusesCurrentIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Rand@1-0-0.revocomp")
+
+	writeFakeBinary(t, path, "name: Rand\ntype: generator\nversion: 1.0.0")
+
+	if _, err := ReadRegistry(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged binaries are not asked for their info again.
+	data, err := os.ReadFile(filepath.Join(dir, registryFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var index registryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	index.Entries[0].Description = "From the index."
+
+	data, err = json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, registryFileName), data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Description != "From the index." {
+		t.Errorf("ReadRegistry() = %+v, want the indexed entry", entries)
+	}
+}
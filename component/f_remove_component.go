@@ -0,0 +1,39 @@
+package component
+
+import (
+	"fmt"
+	"os"
+)
+
+// RemoveComponent deletes an installed version of a component and removes
+// it from the registry.
+func RemoveComponent(name, version string) error {
+	componentDir, err := ComponentDir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := ReadRegistry(componentDir)
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	for _, entry := range entries {
+		if entry.Name != name || entry.Version != version {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("component %s@%s is not installed", name, version)
+	}
+
+	_, err = ReadRegistry(componentDir)
+	return err
+}
@@ -0,0 +1,60 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// RenderDocs renders Markdown documentation of a compiled component from its
+// metadata and the @doc annotations of its parameters.
+func RenderDocs(entry RegistryEntry, params []Param) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "## %s %s\n\n", entry.Name, entry.Version)
+
+	byline := strcase.ToCamel(entry.Type)
+	if entry.Author != "" {
+		byline += " by " + entry.Author
+	}
+	fmt.Fprintf(&builder, "*%s*\n\n", byline)
+
+	if description := strings.TrimSpace(entry.Description); description != "" {
+		fmt.Fprintf(&builder, "%s\n\n", description)
+	}
+
+	if len(params) == 0 {
+		builder.WriteString("This component has no parameters.\n")
+		return builder.String()
+	}
+
+	builder.WriteString("| Parameter | Type | Default | Description |\n")
+	builder.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, param := range params {
+		description := param.Doc
+		if restrictions := param.RestrictionString(); restrictions != "" {
+			description = strings.TrimSpace(description + " Restrictions: " + restrictions + ".")
+		}
+
+		defaultValue := param.DefaultString()
+		if param.Optional {
+			defaultValue = "`" + defaultValue + "`"
+		}
+
+		fmt.Fprintf(&builder, "| `%s` | %s | %s | %s |\n",
+			param.Name,
+			escapeTableCell(param.TypeString()),
+			escapeTableCell(defaultValue),
+			escapeTableCell(description),
+		)
+	}
+
+	return builder.String()
+}
+
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
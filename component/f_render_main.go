@@ -0,0 +1,27 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Renders the main file of a component of the given type.
+func renderMain(kind string, data mainData) (string, error) {
+	tmplData, err := files.ReadFile(fmt.Sprintf("boilerplate/%s/main.tmpl", kind))
+	if err != nil {
+		return "", err
+	}
+
+	mainTmpl, err := template.New("mainTmpl").Parse(string(tmplData))
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := mainTmpl.Execute(&builder, data); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,27 @@
+package component
+
+import (
+	"go/types"
+)
+
+// Returns the supported type that t is declared as, keeping any slice
+// prefix, so that named types such as Direction and aliases resolve to the
+// primitive type they are declared with, whichever file declares them.
+func underlyingType(t types.Type) (string, bool) {
+	prefix := ""
+	if slice, ok := t.(*types.Slice); ok {
+		prefix = "[]"
+		t = slice.Elem()
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "", false
+	}
+
+	if _, ok := typeMap[basic.Name()]; !ok {
+		return "", false
+	}
+
+	return prefix + basic.Name(), true
+}
@@ -1,168 +1,323 @@
 package component
 
 import (
-	"errors"
 	"fmt"
-	"go/ast"
-	"go/parser"
+	"go/importer"
 	"go/token"
+	"go/types"
 	"os"
+	"path/filepath"
 	"revolution/astutil"
 	"strings"
 
-	"golang.org/x/exp/maps"
-	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
-func validateComponent(dir string) error {
+// The import path of the package declaring the notes of modifiers without
+// context.
+const revoutilPath = "github.com/davi4046/revoutil"
 
-	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
-		return err
+// Type checks the package of the component in dir and checks that it
+// declares what the generated main file needs. Every problem found is
+// reported at once in a ValidationError. The checked package is returned for
+// looking up the types of the parameters.
+func validateComponent(dir string) (*types.Package, error) {
+
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); os.IsNotExist(err) {
+		return nil, err
 	}
 
-	yamlData, err := os.ReadFile("revocomp.yaml")
+	yamlData, err := os.ReadFile(filepath.Join(dir, "revocomp.yaml"))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var info Info
 	if err := yaml.Unmarshal(yamlData, &info); err != nil {
-		return err
+		return nil, err
 	}
 
 	if emptyFields := getEmptyFields(info); len(emptyFields) != 0 {
-		return fmt.Errorf(`%s is missing from revocomp.yaml`, strings.Join(emptyFields, ", "))
+		return nil, fmt.Errorf(`%s is missing from revocomp.yaml`, strings.Join(emptyFields, ", "))
+	}
+
+	if info.Type != "generator" && info.Type != "modifier" {
+		return nil, fmt.Errorf("component type is invalid")
 	}
 
-	data, err := os.ReadFile("revocomp.go")
+	fset := token.NewFileSet()
+	astFiles, merged, err := parsePackage(fset, dir)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	astFile, err := parser.ParseFile(token.NewFileSet(), "", data, 0)
+	var problems []string
+
+	report := func(pos token.Pos, format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		if pos.IsValid() {
+			message = fset.Position(pos).String() + ": " + message
+		}
+		problems = append(problems, message)
+	}
+
+	if merged.Name.Name != "main" {
+		report(astFiles[0].Name.Pos(), "package must be named 'main'")
+	}
+
+	hasContext := astutil.FindFuncDeclByName(merged, "GenerateWithContext") != nil ||
+		astutil.FindFuncDeclByName(merged, "ModifyWithContext") != nil
+
+	generated, err := generatedDeclarations(fset, info.Type, hasContext)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			problems = append(problems, err.Error())
+		},
 	}
 
-	// Validate type-specific functions
+	pkg, _ := conf.Check("main", fset, append(astFiles, generated), nil)
+
+	intType := types.Typ[types.Int]
+	float64Type := types.Typ[types.Float64]
+
+	// Validate type-specific declarations
 	switch info.Type {
 	case "generator":
-		// Find and validate 'generator' type.
-		if decl := astutil.FindTypeSpecByName(astFile, "Generator"); decl != nil {
-			if _, ok := decl.Type.(*ast.StructType); !ok {
-				return errors.New("type 'Generator' must be a struct")
-			}
-		} else {
-			return errors.New("type 'Generator' is missing from revocomp.go")
-		}
-		// Find and validate 'newGenerator' function.
-		if decl := astutil.FindFuncDeclByName(astFile, "NewGenerator"); decl != nil {
-			for _, param := range astutil.GetSimpleFields(decl.Type.Params.List) {
-				if !slices.Contains(maps.Keys(typeMap), param.Type) {
-					return fmt.Errorf("parameter %s of function 'NewGenerator' is an unsupported type", param.Name)
-				}
-			}
+		generatorType := validateComponentType(pkg, "Generator", report)
+		validateConstructor(pkg, "NewGenerator", "Generator", generatorType, report)
 
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Results.List),
-				[]astutil.SimpleField{{
-					Name: "",
-					Type: "Generator",
-				}},
-			) {
-				return errors.New("function 'NewGenerator' must have exactly one unnamed result of type 'Generator'")
-			}
-		} else {
-			return errors.New("function 'NewGenerator' is missing from revocomp.go")
+		if generatorType == nil {
+			break
 		}
-		// Find and validate 'generate' function.
-		if decl := astutil.FindFuncDeclByName(astFile, "Generate"); decl != nil {
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Params.List),
-				[]astutil.SimpleField{{
-					Name: "i",
-					Type: "int",
-				}},
-			) {
-				return errors.New("function 'Generate' must have exactly one parameter named 'i' of type 'int'")
-			}
 
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Results.List),
-				[]astutil.SimpleField{{
-					Name: "degree",
-					Type: "int",
-				}, {
-					Name: "duration",
-					Type: "float64",
-				}},
-			) {
-				return errors.New("function 'Generate' must return exactly two results named 'degree' and 'duration' of type 'int' and 'float64'")
+		generate := lookupMethod(pkg, generatorType, "Generate")
+		generateWithContext := lookupMethod(pkg, generatorType, "GenerateWithContext")
+
+		switch {
+		case generate != nil && generateWithContext != nil:
+			report(generateWithContext.Pos(), "only one of the methods 'Generate' and 'GenerateWithContext' may be declared")
+		case generate != nil:
+			if !hasSignature(generate, []types.Type{intType}, []types.Type{intType, float64Type}) {
+				report(generate.Pos(), "method 'Generate' must have the signature Generate(i int) (degree int, duration float64)")
 			}
-		} else {
-			return errors.New("function 'Generate' is missing from revocomp.go")
+		case generateWithContext != nil:
+			contextType := lookupType(pkg, "Context")
+			if !hasSignature(generateWithContext, []types.Type{intType, contextType}, []types.Type{intType, float64Type}) {
+				report(generateWithContext.Pos(), "method 'GenerateWithContext' must have the signature GenerateWithContext(i int, ctx Context) (degree int, duration float64)")
+			}
+		default:
+			report(token.NoPos, "method 'Generate' of type 'Generator' is missing")
 		}
 	case "modifier":
-		// Find and validate 'modifier' type.
-		if decl := astutil.FindTypeSpecByName(astFile, "Modifier"); decl != nil {
-			if _, ok := decl.Type.(*ast.StructType); !ok {
-				return errors.New("type 'Modifier' must be a struct")
-			}
-		} else {
-			return errors.New("type 'Modifier' is missing from revocomp.go")
+		modifierType := validateComponentType(pkg, "Modifier", report)
+		validateConstructor(pkg, "NewModifier", "Modifier", modifierType, report)
+
+		if modifierType == nil {
+			break
 		}
-		// Find and validate 'newModifier' function.
-		if decl := astutil.FindFuncDeclByName(astFile, "NewModifier"); decl != nil {
-			for _, param := range astutil.GetSimpleFields(decl.Type.Params.List) {
-				baseType := strings.TrimPrefix(param.Type, "[]")
-				if !slices.Contains(maps.Keys(typeMap), baseType) {
-					return fmt.Errorf("parameter %s of function 'NewModifier' is an unsupported type", param.Name)
-				}
-			}
 
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Results.List),
-				[]astutil.SimpleField{{
-					Name: "",
-					Type: "Modifier",
-				}},
-			) {
-				return errors.New("function 'NewModifier' must have exactly one unnamed result of type of 'Modifier'")
+		modify := lookupMethod(pkg, modifierType, "Modify")
+		modifyWithContext := lookupMethod(pkg, modifierType, "ModifyWithContext")
+
+		// The type of the notes, which decides the result of Finish.
+		noteType, noteName := lookupImportedType(pkg, revoutilPath, "Note"), "revoutil.Note"
+
+		switch {
+		case modify != nil && modifyWithContext != nil:
+			report(modifyWithContext.Pos(), "only one of the methods 'Modify' and 'ModifyWithContext' may be declared")
+		case modify != nil:
+			if !hasSignature(modify, []types.Type{noteType}, []types.Type{newSlice(noteType)}) {
+				report(modify.Pos(), "method 'Modify' must have the signature Modify(note revoutil.Note) []revoutil.Note")
 			}
-		} else {
-			return errors.New("function 'NewModifier' is missing from revocomp.go")
-		}
-		// Find and validate 'modify' function.
-		if decl := astutil.FindFuncDeclByName(astFile, "Modify"); decl != nil {
-
-			fmt.Println(astutil.GetSimpleFields(decl.Type.Params.List))
-
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Params.List),
-				[]astutil.SimpleField{{
-					Name: "note",
-					Type: "revoutil.Note",
-				}},
-			) {
-				return errors.New("function 'Modify' must have exactly one parameter named 'note' of type 'revoutil.Note'")
+		case modifyWithContext != nil:
+			noteType, noteName = lookupType(pkg, "Note"), "Note"
+			contextType := lookupType(pkg, "Context")
+			if !hasSignature(modifyWithContext, []types.Type{noteType, contextType}, []types.Type{newSlice(noteType)}) {
+				report(modifyWithContext.Pos(), "method 'ModifyWithContext' must have the signature ModifyWithContext(note Note, ctx Context) []Note")
 			}
+		default:
+			report(token.NoPos, "method 'Modify' of type 'Modifier' is missing")
+		}
 
-			if !slices.Equal(
-				astutil.GetSimpleFields(decl.Type.Results.List),
-				[]astutil.SimpleField{{
-					Name: "",
-					Type: "[]revoutil.Note",
-				}},
-			) {
-				return errors.New("function 'Modify' must return exactly one unnamed result of type 'revoutil.Note'")
+		if finish := lookupMethod(pkg, modifierType, "Finish"); finish != nil {
+			if !hasSignature(finish, nil, []types.Type{newSlice(noteType)}) {
+				report(finish.Pos(), "method 'Finish' must have the signature Finish() []%s", noteName)
 			}
-		} else {
-			return errors.New("function 'Modify' is missing from revocomp.go")
 		}
+	}
+
+	if len(problems) != 0 {
+		return nil, ValidationError{Problems: problems}
+	}
+
+	return pkg, nil
+}
+
+// Checks that the type of the component is declared as a struct, and returns
+// it if it is declared at all.
+func validateComponentType(pkg *types.Package, name string, report func(token.Pos, string, ...any)) types.Type {
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		report(token.NoPos, "type '%s' is missing", name)
+		return nil
+	}
+
+	typeName, ok := obj.(*types.TypeName)
+	if !ok {
+		report(obj.Pos(), "'%s' must be a type", name)
+		return nil
+	}
+
+	if _, ok := typeName.Type().Underlying().(*types.Struct); !ok {
+		report(obj.Pos(), "type '%s' must be a struct", name)
+	}
+
+	return typeName.Type()
+}
+
+// Checks that the constructor of the component only takes supported
+// parameters and returns the type of the component.
+func validateConstructor(pkg *types.Package, name, resultName string, result types.Type, report func(token.Pos, string, ...any)) {
+	obj := pkg.Scope().Lookup(name)
+	if obj == nil {
+		report(token.NoPos, "function '%s' is missing", name)
+		return
+	}
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		report(obj.Pos(), "'%s' must be a function", name)
+		return
+	}
+
+	signature := fn.Type().(*types.Signature)
+
+	if signature.Variadic() {
+		report(fn.Pos(), "function '%s' must not be variadic", name)
+	}
+
+	params := signature.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		if !isSupportedParamType(pkg, param.Type()) {
+			report(param.Pos(), "parameter %s of function '%s' is of the unsupported type %s", param.Name(), name, types.TypeString(param.Type(), types.RelativeTo(pkg)))
+		}
+	}
+
+	results := signature.Results()
+	if result != nil && (results.Len() != 1 || results.At(0).Name() != "" || !isIdentical(results.At(0).Type(), result)) {
+		report(fn.Pos(), "function '%s' must have exactly one unnamed result of type '%s'", name, resultName)
+	}
+}
+
+// Reports whether the generated main file can convert arguments to t: the
+// supported primitive types, types of this package declared with them or
+// aliasing them, and slices of either.
+func isSupportedParamType(pkg *types.Package, t types.Type) bool {
+	t = paramType(t)
+	if slice, ok := t.(*types.Slice); ok {
+		t = slice.Elem()
+	}
+
+	switch t := t.(type) {
+	case *types.Basic:
+		_, ok := typeMap[t.Name()]
+		return ok || t.Kind() == types.Invalid
+	case *types.Named:
+		basic, ok := t.Underlying().(*types.Basic)
+		if !ok || t.Obj().Pkg() != pkg {
+			return false
+		}
+		_, ok = typeMap[basic.Name()]
+		return ok
 	default:
-		return fmt.Errorf("component type is invalid")
+		// Aliases are declared in this package, and other types are not
+		// declared with a primitive type.
+		basic, ok := t.Underlying().(*types.Basic)
+		if !ok {
+			return false
+		}
+		_, ok = typeMap[basic.Name()]
+		return ok
 	}
+}
+
+// Returns the method of the type, if any, including those with a pointer
+// receiver, as the generated main file calls them on a variable.
+func lookupMethod(pkg *types.Package, t types.Type, name string) *types.Func {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, pkg, name)
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+func lookupType(pkg *types.Package, name string) types.Type {
+	if typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+		return typeName.Type()
+	}
+	return nil
+}
 
+func lookupImportedType(pkg *types.Package, path, name string) types.Type {
+	for _, imported := range pkg.Imports() {
+		if imported.Path() != path {
+			continue
+		}
+		if typeName, ok := imported.Scope().Lookup(name).(*types.TypeName); ok {
+			return typeName.Type()
+		}
+	}
 	return nil
 }
+
+func newSlice(elem types.Type) types.Type {
+	if elem == nil {
+		return nil
+	}
+	return types.NewSlice(elem)
+}
+
+// Reports whether the function takes and returns values of exactly the given
+// types. A nil type stands for a type that could not be found, which never
+// matches.
+func hasSignature(fn *types.Func, params, results []types.Type) bool {
+	signature := fn.Type().(*types.Signature)
+
+	if signature.Variadic() || signature.Params().Len() != len(params) || signature.Results().Len() != len(results) {
+		return false
+	}
+
+	for i, param := range params {
+		if !isIdentical(signature.Params().At(i).Type(), param) {
+			return false
+		}
+	}
+	for i, result := range results {
+		if !isIdentical(signature.Results().At(i).Type(), result) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Like types.Identical, except that types that failed to type check match
+// anything, as the type checker already reports them.
+func isIdentical(actual, expected types.Type) bool {
+	if isInvalid(actual) {
+		return true
+	}
+	return expected != nil && types.Identical(actual, expected)
+}
+
+func isInvalid(t types.Type) bool {
+	if slice, ok := t.(*types.Slice); ok {
+		t = slice.Elem()
+	}
+	basic, ok := t.(*types.Basic)
+	return ok && basic.Kind() == types.Invalid
+}
@@ -0,0 +1,76 @@
+package component
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Checks the files of an unpacked package against its checksums. Every file
+// must be listed, so that nothing can be slipped into a package.
+func verifyChecksums(dir string) error {
+	file, err := os.Open(filepath.Join(dir, packageChecksumsFile))
+	if err != nil {
+		return fmt.Errorf("package has no checksums: %w", err)
+	}
+	defer file.Close()
+
+	listed := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("invalid line in %s: %s", packageChecksumsFile, line)
+		}
+
+		path, err := packagePath(dir, name)
+		if err != nil {
+			return fmt.Errorf("invalid line in %s: %w", packageChecksumsFile, err)
+		}
+
+		actual, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		if actual != hash {
+			return fmt.Errorf("checksum of %s does not match", name)
+		}
+
+		listed[name] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		// A link could lead outside of the package.
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", name)
+		}
+
+		if name != packageChecksumsFile && !listed[name] {
+			return fmt.Errorf("%s has no checksum", name)
+		}
+
+		return nil
+	})
+}
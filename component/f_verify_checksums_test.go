@@ -0,0 +1,138 @@
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Writes the files of a package to dir along with checksums listing the
+// names in listed, or all files if listed is nil.
+func writeTestPackage(t *testing.T, dir string, files map[string]string, listed map[string]string) {
+	t.Helper()
+
+	if listed == nil {
+		listed = files
+	}
+
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var checksums strings.Builder
+	for name, body := range listed {
+		fmt.Fprintf(&checksums, "%s  %s\n", sha256Hex(body), name)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, packageChecksumsFile), []byte(checksums.String()), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	files := map[string]string{
+		"revocomp.yaml":      "name: Rand",
+		"source/revocomp.go": "package main",
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		listed  map[string]string
+		wantErr string
+	}{
+		{
+			name:  "all files listed",
+			files: files,
+		},
+		{
+			name:    "changed file",
+			files:   map[string]string{"revocomp.yaml": "name: Rand", "source/revocomp.go": "package evil"},
+			listed:  files,
+			wantErr: "checksum of source/revocomp.go does not match",
+		},
+		{
+			name:    "unlisted file",
+			files:   map[string]string{"revocomp.yaml": "name: Rand", "source/revocomp.go": "package main", "bin/evil": "evil"},
+			listed:  files,
+			wantErr: "bin/evil has no checksum",
+		},
+		{
+			name:    "listed file outside of the package",
+			files:   files,
+			listed:  map[string]string{"revocomp.yaml": "name: Rand", "source/revocomp.go": "package main", "../outside": "outside"},
+			wantErr: "../outside is outside of the package",
+		},
+		{
+			name:    "listed absolute path",
+			files:   files,
+			listed:  map[string]string{"revocomp.yaml": "name: Rand", "source/revocomp.go": "package main", "/outside": "outside"},
+			wantErr: "/outside is outside of the package",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "package")
+
+			// A file outside of the package that matches its listed checksum.
+			if err := os.WriteFile(filepath.Join(parent, "outside"), []byte("outside"), 0666); err != nil {
+				t.Fatal(err)
+			}
+
+			writeTestPackage(t, dir, test.files, test.listed)
+
+			err := verifyChecksums(dir)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyChecksums() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("verifyChecksums() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksumsRejectsLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating links requires privileges")
+	}
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "package")
+
+	if err := os.WriteFile(filepath.Join(parent, "outside"), []byte("outside"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestPackage(t, dir, map[string]string{"revocomp.yaml": "name: Rand"},
+		map[string]string{"revocomp.yaml": "name: Rand", "link": "outside"})
+
+	if err := os.Symlink(filepath.Join(parent, "outside"), filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyChecksums(dir); err == nil || !strings.Contains(err.Error(), "link is not a regular file") {
+		t.Fatalf("verifyChecksums() error = %v, want a rejected link", err)
+	}
+}
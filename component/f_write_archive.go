@@ -0,0 +1,97 @@
+package component
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// Writes the files, by their name in the archive, to a tar.gz archive at
+// path, followed by their checksums.
+func writeArchive(path string, files map[string]string) (err error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	names := maps.Keys(files)
+	sort.Strings(names)
+
+	var checksums strings.Builder
+
+	for _, name := range names {
+		hash, err := addArchiveFile(tarWriter, name, files[name])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&checksums, "%s  %s\n", hash, name)
+	}
+
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    packageChecksumsFile,
+		Mode:    0644,
+		Size:    int64(checksums.Len()),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(tarWriter, checksums.String()); err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// Adds a file to the archive and returns its SHA-256.
+func addArchiveFile(tarWriter *tar.Writer, name, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	header, err := tar.FileInfoHeader(stat, "")
+	if err != nil {
+		return "", err
+	}
+	header.Name = name
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return "", err
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tarWriter, hasher), file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,12 @@
+package component
+
+type CompileOptions struct {
+	// Whether to keep the temporary directory the component is built in,
+	// together with its generated main file.
+	KeepTemp bool
+	// The directory of the component source. Defaults to the working
+	// directory.
+	SourceDir string
+	// The platform to build for. Defaults to the current one.
+	GOOS, GOARCH string
+}
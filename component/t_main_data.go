@@ -0,0 +1,8 @@
+package component
+
+// The data the main file templates are rendered with.
+type mainData struct {
+	XSDFileName, Conversions, Args string
+	Params                         []mainParam
+	HasFinish, HasContext          bool
+}
@@ -0,0 +1,8 @@
+package component
+
+// A parameter as parsed by the generated main file.
+type mainParam struct {
+	Name     string
+	Optional bool
+	Default  string
+}
@@ -0,0 +1,56 @@
+package component
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Param describes a parameter of a compiled component, as declared in its
+// XSD.
+type Param struct {
+	Name string
+	// XSD type of the value, or of its elements if it is a list.
+	Type     string
+	List     bool
+	Optional bool
+	Default  string
+	Doc      string
+	// Allowed values, if restricted to a set.
+	Enum []string
+	// Other restrictions, such as minInclusive, by name.
+	Restrictions map[string]string
+}
+
+// TypeString describes the type of the parameter for people, e.g.
+// "list of integer" or "string (up, down)".
+func (p Param) TypeString() string {
+	s := p.Type
+	if p.List {
+		s = "list of " + s
+	}
+	if len(p.Enum) != 0 {
+		s += " (" + strings.Join(p.Enum, ", ") + ")"
+	}
+	return s
+}
+
+// DefaultString returns the default of the parameter, or "required".
+func (p Param) DefaultString() string {
+	if !p.Optional {
+		return "required"
+	}
+	return p.Default
+}
+
+// RestrictionString lists the restrictions other than the allowed values,
+// e.g. "minInclusive=0.25, maxInclusive=10".
+func (p Param) RestrictionString() string {
+	var restrictions []string
+	for _, key := range maps.Keys(p.Restrictions) {
+		restrictions = append(restrictions, key+"="+p.Restrictions[key])
+	}
+	sort.Strings(restrictions)
+	return strings.Join(restrictions, ", ")
+}
@@ -0,0 +1,17 @@
+package component
+
+import "time"
+
+// RegistryEntry describes a compiled component in the components directory.
+type RegistryEntry struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Version     string    `json:"version"`
+	Author      string    `json:"author"`
+	Description string    `json:"description"`
+	Path        string    `json:"path"`
+	ModTime     time.Time `json:"modTime"`
+	Size        int64     `json:"size"`
+	// SHA-256 of the binary.
+	Hash string `json:"hash"`
+}
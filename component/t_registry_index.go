@@ -0,0 +1,12 @@
+package component
+
+// The version of the registry index format. An index of another version,
+// or one written before the format had a version, is rebuilt so that
+// fields added since are read from the binaries.
+const registryVersion = 2
+
+// registryIndex is the content of the registry index file.
+type registryIndex struct {
+	Version int             `json:"version"`
+	Entries []RegistryEntry `json:"entries"`
+}
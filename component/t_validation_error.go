@@ -0,0 +1,12 @@
+package component
+
+import "strings"
+
+// ValidationError lists every problem found in the source of a component.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return "invalid component:\n\t" + strings.Join(e.Problems, "\n\t")
+}
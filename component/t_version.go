@@ -0,0 +1,40 @@
+package component
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A semantic version of a component, without pre-release or build metadata.
+type version struct {
+	major, minor, patch int
+}
+
+func parseVersion(s string) (version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return version{}, fmt.Errorf("invalid version %s", s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, fmt.Errorf("invalid version %s", s)
+		}
+		numbers[i] = n
+	}
+
+	return version{numbers[0], numbers[1], numbers[2]}, nil
+}
+
+func (v version) less(other version) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
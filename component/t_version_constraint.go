@@ -0,0 +1,117 @@
+package component
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A constraint on the version of a component, as written after the name in
+// a component reference:
+//
+//	latest        any version
+//	1             any 1.x.x version, as do 1.x and 1.x.x
+//	1.2           any 1.2.x version, as does 1.2.x
+//	1.2.3         exactly 1.2.3
+//	caret-1.2     1.2.0 or any later 1.x.x version, also written ^1.2
+//	caret-0.2     0.2.0 or any later 0.2.x version, also written ^0.2
+//	tilde-1.2.3   1.2.3 or any later 1.2.x version, also written ~1.2.3
+//
+// The ^ and ~ forms may not appear in XML element names, so projects use
+// the caret- and tilde- forms instead.
+type versionConstraint struct {
+	// The operator, if any, and the leading numbers that are given.
+	operator byte
+	numbers  []int
+}
+
+// The prefixes of the operators, with the forms allowed in XML names first.
+var versionOperators = []struct {
+	prefix   string
+	operator byte
+}{
+	{"caret-", '^'},
+	{"tilde-", '~'},
+	{"^", '^'},
+	{"~", '~'},
+}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var constraint versionConstraint
+
+	if s == "latest" || s == "*" || s == "x" {
+		return constraint, nil
+	}
+
+	original := s
+
+	for _, op := range versionOperators {
+		if strings.HasPrefix(s, op.prefix) {
+			constraint.operator = op.operator
+			s = strings.TrimPrefix(s, op.prefix)
+			break
+		}
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return versionConstraint{}, fmt.Errorf("invalid version constraint %s", original)
+	}
+
+	for i, part := range parts {
+		if part == "x" || part == "*" {
+			// Anything after a wildcard must be a wildcard as well.
+			for _, rest := range parts[i:] {
+				if rest != "x" && rest != "*" {
+					return versionConstraint{}, fmt.Errorf("invalid version constraint %s", original)
+				}
+			}
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return versionConstraint{}, fmt.Errorf("invalid version constraint %s", original)
+		}
+		constraint.numbers = append(constraint.numbers, n)
+	}
+
+	if constraint.operator != 0 && len(constraint.numbers) == 0 {
+		return versionConstraint{}, fmt.Errorf("invalid version constraint %s", original)
+	}
+
+	return constraint, nil
+}
+
+func (c versionConstraint) matches(v version) bool {
+	actual := []int{v.major, v.minor, v.patch}
+
+	// The leading numbers that must be equal.
+	fixed := len(c.numbers)
+	switch c.operator {
+	case '^':
+		// Up to the first number that is not zero, so that ^1.2 matches
+		// 1.3.0 but ^0.2 doesn't match 0.3.0.
+		for i, n := range c.numbers {
+			if n != 0 {
+				fixed = i + 1
+				break
+			}
+		}
+	case '~':
+		if fixed > 2 {
+			fixed = 2
+		}
+	}
+
+	for i := 0; i < fixed; i++ {
+		if actual[i] != c.numbers[i] {
+			return false
+		}
+	}
+
+	// The version must not be lower than the given one.
+	var lower [3]int
+	copy(lower[:], c.numbers)
+
+	return !v.less(version{lower[0], lower[1], lower[2]})
+}
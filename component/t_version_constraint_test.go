@@ -0,0 +1,119 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersionConstraint(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    versionConstraint
+		wantErr bool
+	}{
+		{s: "latest", want: versionConstraint{}},
+		{s: "*", want: versionConstraint{}},
+		{s: "x", want: versionConstraint{}},
+		{s: "1", want: versionConstraint{numbers: []int{1}}},
+		{s: "1.x", want: versionConstraint{numbers: []int{1}}},
+		{s: "1.x.x", want: versionConstraint{numbers: []int{1}}},
+		{s: "1.2", want: versionConstraint{numbers: []int{1, 2}}},
+		{s: "1.2.*", want: versionConstraint{numbers: []int{1, 2}}},
+		{s: "1.2.3", want: versionConstraint{numbers: []int{1, 2, 3}}},
+		{s: "^1.2", want: versionConstraint{operator: '^', numbers: []int{1, 2}}},
+		{s: "caret-1.2", want: versionConstraint{operator: '^', numbers: []int{1, 2}}},
+		{s: "~1.2.3", want: versionConstraint{operator: '~', numbers: []int{1, 2, 3}}},
+		{s: "tilde-1.2.3", want: versionConstraint{operator: '~', numbers: []int{1, 2, 3}}},
+		{s: "", wantErr: true},
+		{s: "1.2.3.4", wantErr: true},
+		{s: "1.x.3", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "a.b", wantErr: true},
+		{s: "^", wantErr: true},
+		{s: "caret-", wantErr: true},
+		{s: "^x", wantErr: true},
+		{s: "^~1", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			got, err := parseVersionConstraint(test.s)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseVersionConstraint(%q) error = %v, wantErr %v", test.s, err, test.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseVersionConstraint(%q) = %+v, want %+v", test.s, got, test.want)
+			}
+		})
+	}
+}
+
+func TestVersionConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"latest", "0.0.1", true},
+		{"latest", "12.3.4", true},
+
+		{"1", "1.0.0", true},
+		{"1", "1.9.9", true},
+		{"1", "2.0.0", false},
+		{"1", "0.9.0", false},
+		{"1.x", "1.4.0", true},
+
+		{"1.2", "1.2.0", true},
+		{"1.2", "1.2.7", true},
+		{"1.2", "1.3.0", false},
+		{"1.2.x", "1.2.7", true},
+
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+
+		{"caret-1.2", "1.2.0", true},
+		{"caret-1.2", "1.9.0", true},
+		{"caret-1.2", "1.1.9", false},
+		{"caret-1.2", "2.0.0", false},
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.2.2", false},
+		{"^1.2.3", "1.3.0", true},
+		{"^0.2", "0.2.0", true},
+		{"^0.2", "0.2.5", true},
+		{"^0.2", "0.3.0", false},
+		{"^0.2", "0.1.9", false},
+		{"^0.2.3", "0.2.4", true},
+		{"^0.2.3", "0.2.2", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^0", "0.9.0", true},
+		{"^0", "1.0.0", false},
+
+		{"tilde-1.2.3", "1.2.3", true},
+		{"tilde-1.2.3", "1.2.9", true},
+		{"tilde-1.2.3", "1.2.2", false},
+		{"tilde-1.2.3", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1.2", "1.3.0", false},
+		{"~1", "1.5.0", true},
+		{"~1", "2.0.0", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint+" "+test.version, func(t *testing.T) {
+			constraint, err := parseVersionConstraint(test.constraint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := parseVersion(test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := constraint.matches(v); got != test.want {
+				t.Errorf("%s matches %s = %v, want %v", test.constraint, test.version, got, test.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package interpret
 
 import (
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 
@@ -10,15 +9,22 @@ import (
 	"github.com/davi4046/revoutil"
 )
 
-func extractKey(el *etree.Element) revoutil.Key {
+func extractKey(el *etree.Element) (revoutil.Key, error) {
 
 	pitch := revoutil.PitchClassMap[el.SelectAttrValue("root", "")]
 
 	scale, err := strconv.Atoi(el.SelectAttrValue("mode", ""))
 	if err != nil {
-		log.Fatalln(err)
+		var key revoutil.Key
+		return key, err
 	}
-	return revoutil.NewKey(pitch, scale)
+	return revoutil.NewKey(pitch, scale), nil
+}
+
+// Returns the root and mode of the key as written in the project.
+func extractKeyName(el *etree.Element) (string, int) {
+	mode, _ := strconv.Atoi(el.SelectAttrValue("mode", ""))
+	return el.SelectAttrValue("root", ""), mode
 }
 
 func extractMeter(el *etree.Element) (revoutil.Meter, error) {
@@ -50,3 +56,37 @@ func extractTempo(el *etree.Element) (float64, error) {
 	}
 	return tempo, nil
 }
+
+func extractGroove(el *etree.Element) (groove, error) {
+	subdivision, err := strconv.ParseUint(el.SelectAttrValue("subdivision", ""), 10, 0)
+	if err != nil {
+		return groove{}, err
+	}
+	if subdivision == 0 {
+		return groove{}, fmt.Errorf("subdivision must be positive")
+	}
+
+	swing, err := strconv.ParseFloat(el.SelectAttrValue("swing", "0.5"), 64)
+	if err != nil {
+		return groove{}, err
+	}
+	if swing <= 0 || swing >= 1 {
+		return groove{}, fmt.Errorf("swing must be between 0 and 1")
+	}
+
+	var accents []float64
+
+	for _, s := range strings.Fields(el.SelectAttrValue("accents", "")) {
+		accent, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return groove{}, err
+		}
+		accents = append(accents, accent)
+	}
+
+	return groove{
+		subdivision: 1 / float64(subdivision),
+		swing:       swing,
+		accents:     accents,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package interpret
+
+import "math"
+
+// Applies the groove to the note. Onsets and ends on every second
+// subdivision of the bar are delayed by the swing, and the velocity is
+// scaled by the accent of the subdivision the note starts on.
+func applyGroove(note Note, groove groove, changes []change) Note {
+
+	// Returns the position of time in subdivisions from the start of its
+	// bar and whether time lies exactly on a subdivision.
+	locate := func(time float64) (float64, bool) {
+		var changeIndex int
+		for changeIndex+1 < len(changes) && time >= changes[changeIndex+1].noteStart {
+			changeIndex++
+		}
+		change := changes[changeIndex]
+
+		barLength := change.meter.GetWholeNotesPerBar()
+		posInBar := math.Mod(time-change.noteStart, barLength)
+
+		position := posInBar / groove.subdivision
+		rounded := math.Round(position)
+
+		return rounded, math.Abs(position-rounded) < 1e-9
+	}
+
+	swing := func(time float64) float64 {
+		position, onGrid := locate(time)
+		if !onGrid || int(position)%2 == 0 {
+			return time
+		}
+		return time + (2*groove.swing-1)*groove.subdivision
+	}
+
+	start := swing(note.Start)
+	end := swing(note.Start + note.Duration)
+
+	if position, onGrid := locate(note.Start); onGrid && len(groove.accents) > 0 && !note.IsPause {
+		accent := groove.accents[int(position)%len(groove.accents)]
+		velocity := math.Round(float64(note.Velocity) * accent)
+		note.Velocity = uint8(math.Max(1, math.Min(127, velocity)))
+	}
+
+	note.Start = start
+	note.Duration = end - start
+
+	return note
+}
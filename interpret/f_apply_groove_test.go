@@ -0,0 +1,76 @@
+package interpret
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyGroove(t *testing.T) {
+	// Eighths swung in a ratio of 2 to 1, accenting every other eighth.
+	g := groove{
+		subdivision: 0.125,
+		swing:       2.0 / 3,
+		accents:     []float64{1.5, 0.5},
+	}
+
+	const third = 1.0 / 12
+
+	tests := []struct {
+		name         string
+		note         Note
+		wantStart    float64
+		wantDuration float64
+		wantVelocity uint8
+	}{
+		{
+			name:         "on the beat, ending on a swung eighth",
+			note:         Note{Start: 0, Duration: 0.125, Velocity: 64},
+			wantStart:    0,
+			wantDuration: 2 * third,
+			wantVelocity: 96,
+		},
+		{
+			name:         "on a swung eighth",
+			note:         Note{Start: 0.125, Duration: 0.125, Velocity: 64},
+			wantStart:    2 * third,
+			wantDuration: third,
+			wantVelocity: 32,
+		},
+		{
+			name:         "on a swung eighth in the second bar",
+			note:         Note{Start: 1.375, Duration: 0.125, Velocity: 64},
+			wantStart:    1.25 + 2*third,
+			wantDuration: third,
+			wantVelocity: 32,
+		},
+		{
+			name:         "between eighths",
+			note:         Note{Start: 0.0625, Duration: 0.0625, Velocity: 64},
+			wantStart:    0.0625,
+			wantDuration: 2*third - 0.0625,
+			wantVelocity: 64,
+		},
+		{
+			name:         "pause",
+			note:         Note{Start: 0.125, Duration: 0.375, IsPause: true},
+			wantStart:    2 * third,
+			wantDuration: 0.5 - 2*third,
+			wantVelocity: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := applyGroove(test.note, g, defaultChanges)
+			if math.Abs(got.Start-test.wantStart) > 1e-9 {
+				t.Errorf("start = %v, want %v", got.Start, test.wantStart)
+			}
+			if math.Abs(got.Duration-test.wantDuration) > 1e-9 {
+				t.Errorf("duration = %v, want %v", got.Duration, test.wantDuration)
+			}
+			if got.Velocity != test.wantVelocity {
+				t.Errorf("velocity = %v, want %v", got.Velocity, test.wantVelocity)
+			}
+		})
+	}
+}
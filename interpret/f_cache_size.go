@@ -0,0 +1,11 @@
+package interpret
+
+import "github.com/spf13/viper"
+
+// Returns the size configured under the key, or the default if none is.
+func cacheSize(key string, defaultSize int) int {
+	if size := viper.GetInt(key); size > 0 {
+		return size
+	}
+	return defaultSize
+}
@@ -0,0 +1,26 @@
+package interpret
+
+// Fits the notes within end according to the overrun behavior.
+// The notes must be sorted by start.
+func clipNotes(notes []Note, end float64, behavior overrun) []Note {
+	if behavior == overrunKeep {
+		return notes
+	}
+
+	var clipped []Note
+
+	for _, note := range notes {
+		if note.Start >= end {
+			break
+		}
+		if note.Start+note.Duration > end {
+			if behavior == overrunDrop {
+				continue
+			}
+			note.Duration = end - note.Start
+		}
+		clipped = append(clipped, note)
+	}
+
+	return clipped
+}
@@ -0,0 +1,68 @@
+package interpret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClipNotes(t *testing.T) {
+	notes := []Note{
+		{Value: 0, Start: 0, Duration: 0.5},
+		{Value: 1, Start: 0.5, Duration: 0.75},
+		{Value: 2, Start: 1.25, Duration: 0.25},
+	}
+
+	tests := []struct {
+		behavior overrun
+		want     []Note
+	}{
+		{
+			behavior: overrunClip,
+			want: []Note{
+				{Value: 0, Start: 0, Duration: 0.5},
+				{Value: 1, Start: 0.5, Duration: 0.5},
+			},
+		},
+		{
+			behavior: overrunDrop,
+			want: []Note{
+				{Value: 0, Start: 0, Duration: 0.5},
+			},
+		},
+		{
+			behavior: overrunKeep,
+			want:     notes,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.behavior), func(t *testing.T) {
+			got := clipNotes(notes, 1, test.behavior)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("clipNotes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKeptOverrunDoesNotShiftLaterNotes(t *testing.T) {
+	notes := clipNotes([]Note{{Value: 5, Start: 0, Duration: 1.5}}, 1, overrunKeep)
+	notes = append(notes, Note{Value: 0, Start: 1, Duration: 0.25})
+
+	var got []noteEvent
+	for _, event := range noteEvents(notes, 4) {
+		event.note = Note{Value: event.note.Value}
+		got = append(got, event)
+	}
+
+	want := []noteEvent{
+		{tick: 0, isOn: true, note: Note{Value: 5}},
+		{tick: 4, isOn: true, note: Note{Value: 0}},
+		{tick: 5, isOn: false, note: Note{Value: 0}},
+		{tick: 6, isOn: false, note: Note{Value: 5}},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("noteEvents() = %v, want %v", got, want)
+	}
+}
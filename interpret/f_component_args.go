@@ -0,0 +1,27 @@
+package interpret
+
+import (
+	"sort"
+
+	"github.com/beevik/etree"
+)
+
+// Returns the args to start the component of the element with: its
+// attributes and the defaults of the parameters it leaves out, as
+// --name=value. They are sorted so that the args, which are part of the
+// cache keys, don't depend on the order of the attributes.
+func componentArgs(xsdDoc *etree.Document, el *etree.Element) []string {
+	attributes := withDefaults(xsdDoc, el)
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+
+	var args []string
+
+	for _, attr := range attributes {
+		args = append(args, "--"+attr.Key+"="+attr.Value)
+	}
+
+	return args
+}
@@ -0,0 +1,20 @@
+package interpret
+
+// Returns the position in the composition of the position within a
+// generation. The position is placed by the first span that contains it,
+// or by the first span if none does.
+func compositionPosition(position float64, spans []generationSpan) float64 {
+	if len(spans) == 0 {
+		return 0
+	}
+
+	span := spans[0]
+	for _, s := range spans {
+		if position >= s.offset && position < s.offset+s.length {
+			span = s
+			break
+		}
+	}
+
+	return span.start + position - span.offset
+}
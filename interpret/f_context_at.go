@@ -0,0 +1,34 @@
+package interpret
+
+import (
+	"math"
+	"revolution/protocol"
+)
+
+// Returns the context of the position in the composition.
+func contextAt(position float64, changes []change) protocol.Context {
+	ctx := protocol.Context{Position: position}
+
+	if len(changes) == 0 {
+		return ctx
+	}
+
+	var changeIndex int
+	for changeIndex+1 < len(changes) && position >= changes[changeIndex+1].noteStart {
+		changeIndex++
+	}
+	change := changes[changeIndex]
+
+	bars := change.barStart + (position-change.noteStart)/change.meter.GetWholeNotesPerBar()
+	bar := math.Floor(bars)
+
+	ctx.Bar = int(bar)
+	ctx.Beat = (bars - bar) * float64(change.meter.Numerator)
+	ctx.Root = change.root
+	ctx.Mode = change.mode
+	ctx.Numerator = int(change.meter.Numerator)
+	ctx.Denominator = int(change.meter.Denominator)
+	ctx.Tempo = change.tempo
+
+	return ctx
+}
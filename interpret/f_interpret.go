@@ -2,13 +2,18 @@ package interpret
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"revolution/builtin"
+	"revolution/cache"
 	"revolution/component"
+	"revolution/protocol"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,931 +21,1238 @@ import (
 	"time"
 
 	"github.com/beevik/etree"
-	"github.com/davi4046/revoutil"
 	"github.com/radovskyb/watcher"
+	"github.com/spf13/viper"
 	"gitlab.com/gomidi/midi/v2"
 	"gitlab.com/gomidi/midi/v2/smf"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
-// Begin interpreting the specified project directory.
-func Interpret(dir string) error {
+// Interpret renders the project in dir whenever it changes, until the
+// context is done. All component processes are stopped before returning.
+func Interpret(ctx context.Context, dir string) error {
 	w := watcher.New()
 
 	w.FilterOps(watcher.Write)
 
 	xsdFilePath := filepath.Join(dir, ".xsd")
 
+	diskCache := cache.Open(dir)
+
 	generations := make(map[string]*generationManager)
 
-	var modifications []modification
+	// Generations of generators no longer in use by path and args, kept for
+	// when they are used again.
+	recentGenerations := newLRUCache[string, cache.Generation](cacheSize("generation_cache_size", 64))
+
+	// Outputs of modifiers by the cache key of their path, args and input.
+	modifications := newLRUCache[string, []protocol.Note](cacheSize("modification_cache_size", 1024))
+
+	modifiers := newModifierPool()
 
 	var player *exec.Cmd
 
-	go func() {
-		for {
-			select {
-			case event := <-w.Event:
-				fmt.Println(event) // Print the event's info.
+	// Closed once the components and the player have been stopped.
+	stopped := make(chan struct{})
 
-				type myKey struct{ channel, track int }
+	// Renders the project file at path. Errors in the project are returned
+	// and leave the interpreter running.
+	render := func(path string) error {
+		type myKey struct{ channel, track int }
 
-				start := time.Now()
+		start := time.Now()
 
-				wantedComponents := make(map[string]string)
+		wantedComponents := make(map[string]string)
 
-				xmlDoc := etree.NewDocument()
-				if err := xmlDoc.ReadFromFile(event.Path); err != nil {
-					fmt.Println("Failed to read XML file")
-					break
-				}
+		xmlDoc := etree.NewDocument()
+		if err := xmlDoc.ReadFromFile(path); err != nil {
+			return fmt.Errorf("failed to read XML file: %w", err)
+		}
 
-				genDefs := xmlDoc.FindElements("//Definitions/GenDef")
+		genDefs := xmlDoc.FindElements("//Definitions/GenDef")
 
-				for _, genDef := range genDefs {
-					childElements := genDef.ChildElements()
-					if len(childElements) == 0 {
-						continue
-					}
-					firstChild := childElements[0]
-					wantedComponents[firstChild.Tag] = "generator"
-				}
+		for _, genDef := range genDefs {
+			childElements := genDef.ChildElements()
+			if len(childElements) == 0 {
+				continue
+			}
+			firstChild := childElements[0]
+			wantedComponents[firstChild.Tag] = "generator"
+		}
 
-				modDefs := xmlDoc.FindElements("//Definitions/ModDef")
+		modDefs := xmlDoc.FindElements("//Definitions/ModDef")
 
-				for _, modDef := range modDefs {
-					childElements := modDef.ChildElements()
-					if len(childElements) == 0 {
-						continue
-					}
-					firstChild := childElements[0]
-					wantedComponents[firstChild.Tag] = "modifier"
-				}
+		for _, modDef := range modDefs {
+			childElements := modDef.ChildElements()
+			if len(childElements) == 0 {
+				continue
+			}
+			firstChild := childElements[0]
+			wantedComponents[firstChild.Tag] = "modifier"
+		}
 
-				addedComponents := make(map[string]string)
+		addedComponents := make(map[string]string)
 
-				xsdDoc := etree.NewDocument()
-				if err := xsdDoc.ReadFromFile(xsdFilePath); err != nil {
-					log.Fatalln("Failed to read XSD file")
-				}
+		xsdDoc := etree.NewDocument()
+		if err := xsdDoc.ReadFromFile(xsdFilePath); err != nil {
+			return fmt.Errorf("failed to read XSD file: %w", err)
+		}
 
-				genDefChoice := xsdDoc.FindElement("//xs:element[@name='GenDef']/xs:complexType/xs:choice")
-				if genDefChoice == nil {
-					log.Fatalln("XSD file is invalid")
-				}
+		genDefChoice := xsdDoc.FindElement("//xs:element[@name='GenDef']/xs:complexType/xs:choice")
+		if genDefChoice == nil {
+			return errors.New("XSD file is invalid")
+		}
 
-				for _, el := range genDefChoice.ChildElements() {
-					refValue := el.SelectAttrValue("ref", "")
-					addedComponents[refValue] = "generator"
-				}
+		for _, el := range genDefChoice.ChildElements() {
+			refValue := el.SelectAttrValue("ref", "")
+			addedComponents[refValue] = "generator"
+		}
 
-				modDefChoice := xsdDoc.FindElement("//xs:element[@name='ModDef']/xs:complexType/xs:choice")
-				if modDefChoice == nil {
-					log.Fatalln("XSD file is invalid")
-				}
+		modDefChoice := xsdDoc.FindElement("//xs:element[@name='ModDef']/xs:complexType/xs:choice")
+		if modDefChoice == nil {
+			return errors.New("XSD file is invalid")
+		}
 
-				for _, el := range modDefChoice.ChildElements() {
-					refValue := el.SelectAttrValue("ref", "")
-					addedComponents[refValue] = "modifier"
-				}
+		for _, el := range modDefChoice.ChildElements() {
+			refValue := el.SelectAttrValue("ref", "")
+			addedComponents[refValue] = "modifier"
+		}
 
-				fmt.Println("Wanted Components:", wantedComponents)
-				fmt.Println("Added Components:", addedComponents)
+		fmt.Println("Wanted Components:", wantedComponents)
+		fmt.Println("Added Components:", addedComponents)
 
-				// Add wanted components that are not yet added
-				for tag, kind := range wantedComponents {
-					if slices.Contains(maps.Keys(addedComponents), tag) {
-						// Component is already added
-						continue
+		// Resolve the versions of added components again, as a
+		// reference like Name-1 may match a newly installed version.
+		for tag, kind := range addedComponents {
+			if !slices.Contains(maps.Keys(wantedComponents), tag) {
+				continue
+			}
+
+			name, constraint, ok := strings.Cut(tag, "-")
+			if !ok {
+				continue
+			}
+
+			if _, ok := builtin.Lookup(name, constraint); ok {
+				continue
+			}
+
+			_, version, found := component.FindComponent(name, kind, constraint)
+			if !found {
+				continue
+			}
+
+			choice := genDefChoice
+			if kind == "modifier" {
+				choice = modDefChoice
+			}
+
+			versionInfo := choice.FindElement(
+				fmt.Sprintf("//xs:element[@ref='%s']/xs:annotation/xs:appinfo[@source='version']", tag),
+			)
+			if versionInfo != nil && versionInfo.Text() == version {
+				continue
+			}
+
+			if versionInfo != nil {
+				fmt.Printf("Warning: %s now resolves to version %s instead of %s\n", tag, version, versionInfo.Text())
+			}
+
+			// Add it again below with the new version.
+			removeComponent(xsdDoc, choice, tag)
+			delete(addedComponents, tag)
+		}
+
+		// Add wanted components that are not yet added
+		for tag, kind := range wantedComponents {
+			if slices.Contains(maps.Keys(addedComponents), tag) {
+				// Component is already added
+				continue
+			}
+
+			name, version, ok := strings.Cut(tag, "-")
+			if !ok {
+				fmt.Println("Please specify version for", tag)
+				continue
+			}
+
+			if definition, ok := builtin.Lookup(name, version); ok && kind == "generator" {
+				xsdDoc.Root().AddChild(definition.Element())
+
+				reference := etree.NewElement("xs:element")
+				reference.CreateAttr("ref", tag)
+
+				annotation := reference.CreateElement("xs:annotation")
+				appinfo := annotation.CreateElement("xs:appinfo")
+				appinfo.SetText(definition.Path())
+
+				genDefChoice.AddChild(reference)
+				continue
+			}
+
+			path, resolvedVersion, found := component.FindComponent(name, kind, version)
+			if !found {
+				fmt.Println("Failed to locate component", tag)
+				continue
+			}
+
+			cmd := exec.Command(path, "xsd")
+			output, err := cmd.Output()
+			if err != nil {
+				fmt.Println("Failed to get XSD for component", tag)
+				continue
+			}
+
+			doc := etree.NewDocument()
+			if err := doc.ReadFromBytes(output); err != nil {
+				fmt.Println("Failed to parse XSD for component", tag)
+			}
+
+			docRoot := doc.Root()
+			if docRoot == nil {
+				return fmt.Errorf("invalid XSD for component %s", tag)
+			}
+
+			// The element is named after the resolved version, but
+			// is referenced by the tag used in the project.
+			docRoot.CreateAttr("name", tag)
+
+			xsdDoc.Root().AddChild(docRoot)
+
+			reference := etree.NewElement("xs:element")
+			reference.CreateAttr("ref", tag)
+
+			// Store path to component
+			annotation := reference.CreateElement("xs:annotation")
+			appinfo := annotation.CreateElement("xs:appinfo")
+			appinfo.SetText(path)
+
+			// Store the version the tag resolved to
+			versionInfo := annotation.CreateElement("xs:appinfo")
+			versionInfo.CreateAttr("source", "version")
+			versionInfo.SetText(resolvedVersion)
+
+			if resolvedVersion != version {
+				fmt.Println("Resolved", tag, "to version", resolvedVersion)
+			}
+
+			if kind == "generator" {
+				genDefChoice.AddChild(reference)
+			} else {
+				modDefChoice.AddChild(reference)
+			}
+		}
+
+		// Remove added components that are no longer wanted
+		for tag, kind := range addedComponents {
+			if slices.Contains(maps.Keys(wantedComponents), tag) {
+				// Component is still wanted
+				continue
+			}
+
+			if kind == "generator" {
+				removeComponent(xsdDoc, genDefChoice, tag)
+			} else {
+				removeComponent(xsdDoc, modDefChoice, tag)
+			}
+
+			fmt.Println("Removed", tag)
+		}
+
+		xsdDoc.IndentTabs()
+
+		if err := xsdDoc.WriteToFile(xsdFilePath); err != nil {
+			return fmt.Errorf("failed to update project XSD: %w", err)
+		}
+
+		/* Generation */
+
+		genChannels := xmlDoc.FindElements("//Channels/GenChannel")
+
+		genItems := make(map[string][]genItem)
+
+		for i, channel := range genChannels {
+			tracks := channel.FindElements("Track")
+			for j, track := range tracks {
+				xmlItems := track.FindElements("Item")
+
+				// Track-wide defaults that items may override.
+				trackTieStr := track.SelectAttrValue("tie", "false")
+				trackOverrunStr := track.SelectAttrValue("overrun", string(overrunClip))
+
+				var currBar float64
+
+				for _, xmlItem := range xmlItems {
+					ref := xmlItem.SelectAttrValue("ref", "none")
+					lengthStr := xmlItem.SelectAttrValue("length", "0")
+					offsetStr := xmlItem.SelectAttrValue("offset", "0")
+					addStr := xmlItem.SelectAttrValue("add", "0")
+					subStr := xmlItem.SelectAttrValue("sub", "0")
+					tieStr := xmlItem.SelectAttrValue("tie", trackTieStr)
+					overrunStr := xmlItem.SelectAttrValue("overrun", trackOverrunStr)
+
+					length, err := strconv.ParseFloat(lengthStr, 64)
+					if err != nil {
+						return fmt.Errorf("invalid length: %s", lengthStr)
 					}
 
-					name, version, ok := strings.Cut(tag, "-")
-					if !ok {
-						fmt.Println("Please specify version for", tag)
-						continue
+					offset, err := strconv.ParseFloat(offsetStr, 64)
+					if err != nil {
+						return fmt.Errorf("invalid offset: %s", offsetStr)
 					}
 
-					path, found := component.FindComponent(name, kind, version)
-					if !found {
-						fmt.Println("Failed to locate component", tag)
-						continue
+					add, err := strconv.Atoi(addStr)
+					if err != nil {
+						return fmt.Errorf("invalid add: %s", addStr)
 					}
 
-					cmd := exec.Command(path, "xsd")
-					output, err := cmd.Output()
+					sub, err := strconv.Atoi(subStr)
 					if err != nil {
-						fmt.Println("Failed to get XSD for component", tag)
-						continue
+						return fmt.Errorf("invalid sub: %s", subStr)
 					}
 
-					doc := etree.NewDocument()
-					if err := doc.ReadFromBytes(output); err != nil {
-						fmt.Println("Failed to parse XSD for component", tag)
+					tie, err := strconv.ParseBool(tieStr)
+					if err != nil {
+						return fmt.Errorf("invalid tie: %s", tieStr)
 					}
 
-					docRoot := doc.Root()
-					if docRoot == nil {
-						log.Fatalln("Invalid XSD for component", tag)
+					overrun, err := stringToOverrun(overrunStr)
+					if err != nil {
+						return err
 					}
 
-					xsdDoc.Root().AddChild(docRoot)
+					start := currBar
+					currBar += length
+					end := currBar
+
+					genItems[ref] = append(genItems[ref],
+						genItem{
+							channel:   i,
+							track:     j,
+							barStart:  start,
+							barEnd:    end,
+							barOffset: offset,
+							add:       add,
+							sub:       sub,
+							tie:       tie,
+							overrun:   overrun,
+						},
+					)
+				}
+			}
+		}
 
-					reference := etree.NewElement("xs:element")
-					reference.CreateAttr("ref", tag)
+		var changes []change
 
-					// Store path to component
-					annotation := reference.CreateElement("xs:annotation")
-					appinfo := annotation.CreateElement("xs:appinfo")
-					appinfo.SetText(path)
+		keyEl := xmlDoc.FindElement("//Key")
+		if keyEl == nil {
+			return errors.New("please specify key")
+		}
+		meterEl := xmlDoc.FindElement("//Meter")
+		if meterEl == nil {
+			return errors.New("please specify meter")
+		}
+		tempoEl := xmlDoc.FindElement("//Tempo")
+		if tempoEl == nil {
+			return errors.New("please specify tempo")
+		}
 
-					if kind == "generator" {
-						genDefChoice.AddChild(reference)
-					} else {
-						modDefChoice.AddChild(reference)
-					}
-				}
+		key, err := extractKey(keyEl)
+		if err != nil {
+			return fmt.Errorf("invalid key mode: %s", keyEl.SelectAttrValue("mode", ""))
+		}
+		root, mode := extractKeyName(keyEl)
+		meter, err := extractMeter(meterEl)
+		if err != nil {
+			return fmt.Errorf("invalid meter: %s", meterEl.Text())
+		}
+		tempo, err := extractTempo(tempoEl)
+		if err != nil {
+			return fmt.Errorf("invalid tempo: %s", tempoEl.Text())
+		}
 
-				// Remove added components that are no longer wanted
-				for tag, kind := range addedComponents {
-					if slices.Contains(maps.Keys(wantedComponents), tag) {
-						// Component is still wanted
-						continue
-					}
+		changes = []change{
+			{
+				barStart: 0,
+				key:      key,
+				meter:    meter,
+				tempo:    tempo,
+				root:     root,
+				mode:     mode,
+			},
+		}
 
-					element := xsdDoc.FindElement(
-						fmt.Sprintf("//xs:element[@name='%s']", tag),
-					)
-					xsdDoc.Root().RemoveChild(element)
+		for _, changeEl := range xmlDoc.FindElements("//Changes/Change") {
 
-					if kind == "generator" {
-						referenceElement := genDefChoice.FindElement(
-							fmt.Sprintf("//xs:element[@ref='%s']", tag),
-						)
-						genDefChoice.RemoveChild(referenceElement)
-					} else {
-						referenceElement := modDefChoice.FindElement(
-							fmt.Sprintf("//xs:element[@ref='%s']", tag),
-						)
-						modDefChoice.RemoveChild(referenceElement)
-					}
+			var change change
 
-					fmt.Println("Removed", tag)
+			barStr := changeEl.SelectAttrValue("bar", "")
+			bar, err := strconv.ParseFloat(barStr, 64)
+			if err != nil {
+				return fmt.Errorf("invalid bar: %s", barStr)
+			}
+			change.barStart = bar
+
+			keyEl := changeEl.FindElement("Key")
+			meterEl := changeEl.FindElement("Meter")
+			tempoEl := changeEl.FindElement("Tempo")
+
+			if keyEl == nil {
+				// Key remains the same
+				change.key = changes[len(changes)-1].key
+				change.root = changes[len(changes)-1].root
+				change.mode = changes[len(changes)-1].mode
+			} else {
+				key, err := extractKey(keyEl)
+				if err != nil {
+					return fmt.Errorf("invalid key mode: %s", keyEl.SelectAttrValue("mode", ""))
 				}
+				change.key = key
+				change.root, change.mode = extractKeyName(keyEl)
+			}
+			if meterEl == nil {
+				// Meter remains the same
+				change.meter = changes[len(changes)-1].meter
+			} else {
+				meter, err := extractMeter(meterEl)
+				if err != nil {
+					return fmt.Errorf("invalid meter: %s", meterEl.Text())
+				}
+				change.meter = meter
+			}
 
-				xsdDoc.IndentTabs()
-
-				if err := xsdDoc.WriteToFile(xsdFilePath); err != nil {
-					log.Fatalln("Failed to update project XSD")
+			if tempoEl == nil {
+				// Tempo remains the same
+				change.tempo = changes[len(changes)-1].tempo
+			} else {
+				tempo, err := extractTempo(tempoEl)
+				if err != nil {
+					return fmt.Errorf("invalid tempo: %s", tempoEl.Text())
 				}
+				change.tempo = tempo
+			}
 
-				/* Generation */
+			changes = append(changes, change)
+		}
+		for i := range changes {
+			changes[i].noteStart = barToWholeNote(changes[i].barStart, changes)
+		}
 
-				genChannels := xmlDoc.FindElements("//Channels/GenChannel")
+		fmt.Printf("changes:\n%v\n", changes)
 
-				genItems := make(map[string][]genItem)
+		for _, id := range maps.Keys(genItems) {
+			for i, genItem := range genItems[id] {
+				genItem.noteStart = barToWholeNote(genItem.barStart, changes)
+				genItem.noteEnd = barToWholeNote(genItem.barEnd, changes)
 
-				for i, channel := range genChannels {
-					tracks := channel.FindElements("Track")
-					for j, track := range tracks {
-						xmlItems := track.FindElements("Item")
+				genItem.noteOffset = barToWholeNote(genItem.barStart+math.Abs(genItem.barOffset), changes) - genItem.noteStart
+				if genItem.barOffset < 0 {
+					genItem.noteOffset *= -1
 
-						var currBar float64
+				}
+				genItems[id][i] = genItem
+			}
+		}
 
-						for _, xmlItem := range xmlItems {
-							ref := xmlItem.SelectAttrValue("ref", "none")
-							lengthStr := xmlItem.SelectAttrValue("length", "0")
-							offsetStr := xmlItem.SelectAttrValue("offset", "0")
-							addStr := xmlItem.SelectAttrValue("add", "0")
-							subStr := xmlItem.SelectAttrValue("sub", "0")
+		newSettings := make(map[string]*generationSettings)
 
-							length, err := strconv.ParseFloat(lengthStr, 64)
-							if err != nil {
-								log.Fatalln(err)
-							}
+		for _, id := range maps.Keys(genItems) {
 
-							offset, err := strconv.ParseFloat(offsetStr, 64)
-							if err != nil {
-								log.Fatalln(err)
-							}
+			if id == "none" {
+				continue
+			}
 
-							add, err := strconv.Atoi(addStr)
-							if err != nil {
-								log.Fatalln(err)
-							}
+			var generationStart float64
+			var generationEnd float64
 
-							sub, err := strconv.Atoi(subStr)
-							if err != nil {
-								log.Fatalln(err)
-							}
+			var spans []generationSpan
 
-							start := currBar
-							currBar += length
-							end := currBar
-
-							genItems[ref] = append(genItems[ref],
-								genItem{
-									channel:   i,
-									track:     j,
-									barStart:  start,
-									barEnd:    end,
-									barOffset: offset,
-									add:       add,
-									sub:       sub,
-								},
-							)
-						}
-					}
-				}
+			for i, genItem := range genItems[id] {
+
+				length := genItem.noteEnd - genItem.noteStart
 
-				var changes []change
+				spans = append(spans, generationSpan{
+					offset: genItem.noteOffset,
+					start:  genItem.noteStart,
+					length: length,
+				})
 
-				keyEl := xmlDoc.FindElement("//Key")
-				if keyEl == nil {
-					fmt.Println("Please specify key")
+				if i == 0 {
+					generationStart = genItem.noteOffset
+					generationEnd = genItem.noteOffset + length
 					continue
 				}
-				meterEl := xmlDoc.FindElement("//Meter")
-				if meterEl == nil {
-					fmt.Println("Please specify meter")
-					continue
+				if genItem.noteOffset < generationStart {
+					generationStart = genItem.noteOffset
 				}
-				tempoEl := xmlDoc.FindElement("//Tempo")
-				if tempoEl == nil {
-					fmt.Println("Please specify tempo")
-					continue
+				if genItem.noteOffset+length > generationEnd {
+					generationEnd = genItem.noteOffset + length
 				}
+			}
 
-				key := extractKey(keyEl)
-				meter, err := extractMeter(meterEl)
-				if err != nil {
-					log.Fatalln("Invalid Meter:", meterEl.Text())
+			newSettings[id] = &generationSettings{
+				start:   generationStart,
+				end:     generationEnd,
+				spans:   spans,
+				changes: changes,
+			}
+		}
+
+		for _, genDef := range genDefs {
+			id := genDef.SelectAttrValue("id", "")
+
+			if newSettings[id] == nil {
+				continue
+			}
+
+			childElements := genDef.ChildElements()
+
+			if len(childElements) == 0 {
+				continue
+			}
+
+			firstChild := childElements[0]
+
+			appinfo := genDefChoice.FindElement(
+				fmt.Sprintf("//xs:element[@ref='%s']/xs:annotation/xs:appinfo", firstChild.Tag),
+			)
+			if appinfo == nil {
+				// The component could not be added to the XSD.
+				fmt.Printf("Skipping generator %s: %s is not available\n", id, firstChild.Tag)
+				delete(newSettings, id)
+				continue
+			}
+
+			path := appinfo.Text()
+
+			args := componentArgs(xsdDoc, firstChild)
+
+			// Built-in generators have optional parameters, so their
+			// values are matched to the parameters by name.
+			if definition, ok := builtin.LookupPath(path); ok {
+				values := make(map[string]string)
+				for _, attr := range withDefaults(xsdDoc, firstChild) {
+					values[attr.Key] = attr.Value
 				}
-				tempo, err := extractTempo(tempoEl)
+				builtinArgs, err := definition.Args(values, dir)
 				if err != nil {
-					log.Fatalln("Invalid Tempo:", tempoEl.Text())
+					fmt.Printf("Skipping generator %s: %v\n", id, err)
+					delete(newSettings, id)
+					continue
 				}
+				args = builtinArgs
+			}
+
+			newSettings[id].path = path
+			newSettings[id].args = args
+		}
 
-				changes = []change{
-					{
-						barStart: 0,
-						key:      key,
-						meter:    meter,
-						tempo:    tempo,
-					},
+		// Stop the generators of definitions that are no longer used.
+		for id, g := range generations {
+			if _, ok := newSettings[id]; !ok {
+				g.close()
+				delete(generations, id)
+			}
+		}
+
+		var wg sync.WaitGroup
+
+		wg.Add(len(newSettings))
+
+		for id, settings := range newSettings {
+			if _, ok := generations[id]; !ok {
+				generations[id] = &generationManager{
+					cache:  diskCache,
+					recent: recentGenerations,
 				}
+			}
+			fmt.Println("updating:", id)
+			go generations[id].update(*settings, &wg)
+		}
 
-				for _, changeEl := range xmlDoc.FindElements("//Changes/Change") {
+		wg.Wait()
 
-					var change change
+		hits, misses, entries := recentGenerations.stats()
+		fmt.Printf("generation cache: %d hits, %d misses, %d entries\n", hits, misses, entries)
 
-					barStr := changeEl.SelectAttrValue("bar", "")
-					bar, err := strconv.ParseFloat(barStr, 64)
-					if err != nil {
-						log.Fatalln("Invalid Bar:", barStr)
-					}
-					change.barStart = bar
+		for id, g := range generations {
+			fmt.Printf("%s:\n%v\n", id, g.generation)
+		}
 
-					keyEl := changeEl.FindElement("Key")
-					meterEl := changeEl.FindElement("Meter")
-					tempoEl := changeEl.FindElement("Tempo")
+		var allNotes []Note
 
-					if keyEl == nil {
-						// Key remains the same
-						change.key = changes[len(changes)-1].key
-					} else {
-						change.key = extractKey(keyEl)
-					}
-					if meterEl == nil {
-						// Meter remains the same
-						change.meter = changes[len(changes)-1].meter
-					} else {
-						meter, err := extractMeter(meterEl)
-						if err != nil {
-							log.Fatalln("Invalid Time:", meterEl.Text())
-						}
-						change.meter = meter
-					}
+		for genId, genItems := range genItems {
 
-					if tempoEl == nil {
-						// Tempo remains the same
-						change.tempo = changes[len(changes)-1].tempo
-					} else {
-						tempo, err := extractTempo(tempoEl)
-						if err != nil {
-							log.Fatalln("Invalid Tempo:", tempoEl.Text())
-						}
-						change.tempo = tempo
-					}
+			for _, genItem := range genItems {
 
-					changes = append(changes, change)
+				if genId == "none" {
+					allNotes = append(allNotes, Note{
+						Start:    genItem.noteStart,
+						Duration: genItem.noteEnd - genItem.noteStart,
+						Channel:  genItem.channel,
+						Track:    genItem.track,
+						IsPause:  true,
+					})
+					continue
 				}
-				for i := range changes {
-					changes[i].noteStart = barToWholeNote(changes[i].barStart, changes)
+
+				generation, ok := generations[genId]
+				if !ok {
+					// The generator was skipped.
+					continue
 				}
 
-				fmt.Printf("changes:\n%v\n", changes)
+				notes := getFromTo(generation.generation, genItem.noteOffset,
+					genItem.noteOffset+genItem.noteEnd-genItem.noteStart)
 
-				for _, id := range maps.Keys(genItems) {
-					for i, genItem := range genItems[id] {
-						genItem.noteStart = barToWholeNote(genItem.barStart, changes)
-						genItem.noteEnd = barToWholeNote(genItem.barEnd, changes)
+				copiedNotes := make([]Note, len(notes))
+				copy(copiedNotes, notes)
 
-						genItem.noteOffset = barToWholeNote(genItem.barStart+math.Abs(genItem.barOffset), changes) - genItem.noteStart
-						if genItem.barOffset < 0 {
-							genItem.noteOffset *= -1
+				for i := range copiedNotes {
+					copiedNotes[i].Start -= genItem.noteOffset
+					copiedNotes[i].Start += genItem.noteStart
 
-						}
-						genItems[id][i] = genItem
-					}
-				}
+					copiedNotes[i].Channel = genItem.channel
+					copiedNotes[i].Track = genItem.track
 
-				newSettings := make(map[string]*generationSettings)
+					copiedNotes[i].Value += genItem.add - genItem.sub
+				}
 
-				for _, id := range maps.Keys(genItems) {
+				copiedNotes = clipNotes(copiedNotes, genItem.noteEnd, genItem.overrun)
 
-					if id == "none" {
-						continue
-					}
+				if genItem.tie && len(copiedNotes) > 0 {
+					copiedNotes[0].Tie = true
+				}
 
-					var generationStart float64
-					var generationEnd float64
+				allNotes = append(allNotes, copiedNotes...)
+			}
+		}
 
-					for i, genItem := range genItems[id] {
+		sort.SliceStable(allNotes, func(i int, j int) bool {
+			return allNotes[i].Start < allNotes[j].Start
+		})
 
-						length := genItem.noteEnd - genItem.noteStart
+		func() {
+			var changeIndex int
 
-						if i == 0 {
-							generationStart = genItem.noteOffset
-							generationEnd = genItem.noteOffset + length
-							continue
-						}
-						if genItem.noteOffset < generationStart {
-							generationStart = genItem.noteOffset
-						}
-						if genItem.noteOffset+length > generationEnd {
-							generationEnd = genItem.noteOffset + length
-						}
-					}
-
-					newSettings[id] = &generationSettings{
-						start: generationStart,
-						end:   generationEnd,
+			for i := range allNotes {
+				for changeIndex+1 < len(changes) {
+					if allNotes[i].Start >= changes[changeIndex+1].noteStart {
+						changeIndex++
+					} else {
+						break
 					}
 				}
+				allNotes[i].Value = changes[changeIndex].key.DegreeToMIDI(allNotes[i].Value)
+			}
+		}()
 
-				for _, genDef := range genDefs {
-					id := genDef.SelectAttrValue("id", "")
+		allNotes = tieNotes(allNotes)
 
-					if newSettings[id] == nil {
-						continue
-					}
+		/* Modification */
 
-					childElements := genDef.ChildElements()
+		modChannels := xmlDoc.FindElements("//Channels/ModChannel")
 
-					if len(childElements) == 0 {
-						continue
+		modItems := make(map[string][]modItem)
+
+		for _, channel := range modChannels {
+			tracks := channel.FindElements("Track")
+			for _, track := range tracks {
+				xmlItems := track.FindElements("Item")
+
+				var currBar float64
+
+				for _, xmlItem := range xmlItems {
+					ref := xmlItem.SelectAttrValue("ref", "none")
+					lengthStr := xmlItem.SelectAttrValue("length", "0")
+					targetStr := xmlItem.SelectAttrValue("target", "")
+					windowStr := xmlItem.SelectAttrValue("window", "0")
+
+					length, err := strconv.ParseFloat(lengthStr, 64)
+					if err != nil {
+						return fmt.Errorf("invalid length: %s", lengthStr)
 					}
 
-					firstChild := childElements[0]
+					window, err := strconv.ParseFloat(windowStr, 64)
+					if err != nil {
+						return fmt.Errorf("invalid window: %s", windowStr)
+					}
 
-					appinfo := genDefChoice.FindElement(
-						fmt.Sprintf("//xs:element[@ref='%s']/xs:annotation/xs:appinfo", firstChild.Tag),
+					start := currBar
+					currBar += length
+					end := currBar
+
+					modItems[ref] = append(modItems[ref],
+						modItem{
+							barStart: start,
+							barEnd:   end,
+							target:   targetStr,
+							window:   window,
+						},
 					)
+				}
+			}
+		}
 
-					path := appinfo.Text()
+		if err := func() error {
+			var jobs []modJob
 
-					attributes := firstChild.Attr
+			for _, modDef := range modDefs {
 
-					sort.Slice(attributes, func(i, j int) bool {
-						return attributes[i].Key < attributes[j].Key
-					})
+				id := modDef.SelectAttrValue("id", "")
 
-					var args []string
+				if modItems[id] == nil {
+					continue
+				}
 
-					for _, attr := range attributes {
-						args = append(args, attr.Value)
-					}
+				childElements := modDef.ChildElements()
 
-					sort.Slice(args, func(i, j int) bool {
-						return firstChild.Attr[i].Key < firstChild.Attr[j].Key
-					})
+				if len(childElements) == 0 {
+					continue
+				}
 
-					newSettings[id].path = path
-					newSettings[id].args = args
+				firstChild := childElements[0]
+
+				appinfo := modDefChoice.FindElement(
+					fmt.Sprintf("//xs:element[@ref='%s']/xs:annotation/xs:appinfo", firstChild.Tag),
+				)
+				if appinfo == nil {
+					// The component could not be added to the XSD.
+					fmt.Printf("Skipping modifier %s: %s is not available\n", id, firstChild.Tag)
+					continue
 				}
 
-				var wg sync.WaitGroup
+				path := appinfo.Text()
 
-				wg.Add(len(newSettings))
+				args := componentArgs(xsdDoc, firstChild)
 
-				for id, settings := range newSettings {
-					if _, ok := generations[id]; !ok {
-						generations[id] = &generationManager{}
+				explicitStarts := modifiers.contextCapability(path, args)
+
+				for _, modItem := range modItems[id] {
+					target, err := stringToTarget(modItem.target)
+					if err != nil {
+						return err
 					}
-					fmt.Println("updating:", id)
-					go generations[id].update(*settings, &wg)
-				}
 
-				wg.Wait()
+					jobs = append(jobs, modJob{
+						path:        path,
+						args:        args,
+						target:      target,
+						start:       barToWholeNote(modItem.barStart, changes),
+						end:         barToWholeNote(modItem.barEnd, changes),
+						windowStart: barToWholeNote(modItem.barStart-modItem.window, changes),
+						windowEnd:   barToWholeNote(modItem.barEnd+modItem.window, changes),
 
-				for id, g := range generations {
-					fmt.Printf("%s:\n%v\n", id, g.generation)
+						explicitStarts: explicitStarts,
+					})
 				}
+			}
 
-				var allNotes []Note
+			workers := viper.GetInt("modification_workers")
+			if workers <= 0 {
+				workers = runtime.NumCPU()
+			}
 
-				for genId, genItems := range genItems {
+			// Returns the output of the modifier, reusing a previous
+			// modification of the same input if possible.
+			modify := func(job modJob, input modificationInput) []protocol.Note {
+				key, err := diskCache.Key(job.path, job.args, input)
+				if err != nil {
+					// Leave the notes unmodified.
+					fmt.Println("Modification failed:", err)
+					return input.Notes
+				}
 
-					for _, genItem := range genItems {
+				if output, ok := modifications.get(key); ok {
+					return output
+				}
 
-						if genId == "none" {
-							allNotes = append(allNotes, Note{
-								Start:    genItem.noteStart,
-								Duration: genItem.noteEnd - genItem.noteStart,
-								Channel:  genItem.channel,
-								Track:    genItem.track,
-								IsPause:  true,
-							})
-							continue
-						}
+				modification, err := newCachedModification(diskCache, modifiers, key, job.path, job.args, input)
+				if err != nil {
+					// Leave the notes unmodified.
+					fmt.Println("Modification failed:", err)
+					return input.Notes
+				}
 
-						notes := getFromTo(generations[genId].generation, genItem.noteOffset,
-							genItem.noteOffset+genItem.noteEnd-genItem.noteStart)
+				modifications.put(key, modification.output)
 
-						copiedNotes := make([]Note, len(notes))
-						copy(copiedNotes, notes)
+				return modification.output
+			}
 
-						for i := range copiedNotes {
-							copiedNotes[i].Start -= genItem.noteOffset
-							copiedNotes[i].Start += genItem.noteStart
+			// Independent jobs run concurrently. Their inputs are taken
+			// and their outputs are merged in document order, so the
+			// result is the same as when running them one by one, except
+			// that notes a modifier moves to another channel or track, or
+			// lengthens past the end of its item, are not seen by the
+			// other jobs of its level.
+			for _, level := range modJobLevels(jobs) {
+				inputs := make([]modificationInput, len(level))
+				currTimes := make([]map[myKey]float64, len(level))
 
-							copiedNotes[i].Channel = genItem.channel
-							copiedNotes[i].Track = genItem.track
+				for l, jobIndex := range level {
+					job := jobs[jobIndex]
 
-							copiedNotes[i].Value += genItem.add - genItem.sub
-						}
+					i, isNoteOnFrom := binarySearchNote(allNotes, job.start)
+					j, _ := binarySearchNote(allNotes, job.end)
 
-						allNotes = append(allNotes, copiedNotes...)
+					if !isNoteOnFrom && i > 0 {
+						i -= 1
 					}
-				}
 
-				sort.SliceStable(allNotes, func(i int, j int) bool {
-					return allNotes[i].Start < allNotes[j].Start
-				})
+					notesInRange := make([]Note, len(allNotes[i:j]))
+					copy(notesInRange, allNotes[i:j])
 
-				func() {
-					var changeIndex int
+					var targetNotes []Note
 
-					for i := range allNotes {
-						for changeIndex+1 < len(changes) {
-							if allNotes[i].Start >= changes[changeIndex+1].noteStart {
-								changeIndex++
-							} else {
-								break
-							}
+					for k := range notesInRange {
+						if !slices.Contains(job.target.channels, notesInRange[k].Channel) {
+							continue
 						}
-						allNotes[i].Value = changes[changeIndex].key.DegreeToMIDI(allNotes[i].Value)
+						if !slices.Contains(job.target.tracks, notesInRange[k].Track) {
+							continue
+						}
+
+						allNotesIndex := i + k - len(targetNotes)
+						allNotes = slices.Delete(allNotes, allNotesIndex, allNotesIndex+1)
+
+						targetNotes = append(targetNotes, notesInRange[k])
 					}
-				}()
 
-				/* Modification */
+					// Convert notes to protocol notes
+
+					currTime := make(map[myKey]float64)
 
-				modChannels := xmlDoc.FindElements("//Channels/ModChannel")
+					var input modificationInput
 
-				modItems := make(map[string][]modItem)
+					for _, note := range targetNotes {
+						input.Notes = append(input.Notes, toProtocolNote(note))
 
-				for _, channel := range modChannels {
-					tracks := channel.FindElements("Track")
-					for _, track := range tracks {
-						xmlItems := track.FindElements("Item")
+						if _, ok := currTime[myKey{note.Channel, note.Track}]; !ok {
+							currTime[myKey{note.Channel, note.Track}] = note.Start
+						}
+					}
 
-						var currBar float64
+					sort.SliceStable(input.Notes, func(i, j int) bool {
+						a, b := input.Notes[i], input.Notes[j]
+						if a.Channel != b.Channel {
+							return a.Channel < b.Channel
+						}
+						if a.Track != b.Track {
+							return a.Track < b.Track
+						}
+						return *a.Start < *b.Start
+					})
 
-						for _, xmlItem := range xmlItems {
-							ref := xmlItem.SelectAttrValue("ref", "none")
-							lengthStr := xmlItem.SelectAttrValue("length", "0")
-							targetStr := xmlItem.SelectAttrValue("target", "")
+					for _, note := range input.Notes {
+						input.Contexts = append(input.Contexts, contextAt(*note.Start, changes))
+					}
 
-							length, err := strconv.ParseFloat(lengthStr, 64)
-							if err != nil {
-								log.Fatalln(err)
+					if job.windowStart < job.start || job.windowEnd > job.end {
+						for _, note := range allNotes {
+							if !slices.Contains(job.target.channels, note.Channel) ||
+								!slices.Contains(job.target.tracks, note.Track) {
+								continue
+							}
+							if note.Start >= job.windowStart && note.Start < job.start {
+								input.Before = append(input.Before, toProtocolNote(note))
+							}
+							if note.Start >= job.end && note.Start < job.windowEnd {
+								input.After = append(input.After, toProtocolNote(note))
 							}
-
-							start := currBar
-							currBar += length
-							end := currBar
-
-							modItems[ref] = append(modItems[ref],
-								modItem{
-									barStart: start,
-									barEnd:   end,
-									target:   targetStr,
-								},
-							)
 						}
 					}
+
+					inputs[l] = input
+					currTimes[l] = currTime
 				}
 
-				func() {
-					var usedModifications []int
+				results := make([][]protocol.Note, len(level))
 
-					for _, modDef := range modDefs {
+				var wg sync.WaitGroup
 
-						id := modDef.SelectAttrValue("id", "")
+				semaphore := make(chan struct{}, workers)
 
-						if modItems[id] == nil {
-							continue
-						}
+				for l, jobIndex := range level {
+					wg.Add(1)
 
-						childElements := modDef.ChildElements()
+					go func(l int, job modJob) {
+						defer wg.Done()
 
-						if len(childElements) == 0 {
-							continue
-						}
+						semaphore <- struct{}{}
+						defer func() { <-semaphore }()
+
+						results[l] = modify(job, inputs[l])
+					}(l, jobs[jobIndex])
+				}
 
-						firstChild := childElements[0]
+				wg.Wait()
 
-						appinfo := modDefChoice.FindElement(
-							fmt.Sprintf("//xs:element[@ref='%s']/xs:annotation/xs:appinfo", firstChild.Tag),
-						)
+				for l, result := range results {
+					currTime := currTimes[l]
 
-						path := appinfo.Text()
+					for _, note := range result {
+						start := currTime[myKey{note.Channel, note.Track}]
 
-						attributes := firstChild.Attr
+						// Notes with an explicit start are placed there, the others
+						// follow the previous note of their channel and track.
+						if note.Start != nil {
+							start = *note.Start
+						} else {
+							currTime[myKey{note.Channel, note.Track}] += note.Duration
+						}
 
-						sort.Slice(attributes, func(i, j int) bool {
-							return attributes[i].Key < attributes[j].Key
+						allNotes = append(allNotes, Note{
+							Value:    note.Value,
+							Start:    start,
+							Duration: note.Duration,
+							Velocity: defaultVelocity,
+							Channel:  note.Channel,
+							Track:    note.Track,
+							IsPause:  note.IsPause,
 						})
+					}
+				}
 
-						var args []string
+				sort.SliceStable(allNotes, func(i int, j int) bool {
+					return allNotes[i].Start < allNotes[j].Start
+				})
+			}
 
-						for _, attr := range attributes {
-							args = append(args, attr.Value)
-						}
+			modifiers.prune()
 
-						for _, modItem := range modItems[id] {
-							target, err := stringToTarget(modItem.target)
-							if err != nil {
-								log.Fatalln(err)
-							}
+			hits, misses, entries := modifications.stats()
+			fmt.Printf("modification cache: %d hits, %d misses, %d entries\n", hits, misses, entries)
 
-							wholeNoteStart := barToWholeNote(modItem.barStart, changes)
-							wholeNoteEnd := barToWholeNote(modItem.barEnd, changes)
+			return nil
+		}(); err != nil {
+			return err
+		}
 
-							i, isNoteOnFrom := binarySearchNote(allNotes, wholeNoteStart)
-							j, _ := binarySearchNote(allNotes, wholeNoteEnd)
+		/* Groove */
 
-							if !isNoteOnFrom {
-								i -= 1
-							}
+		if err := func() error {
+			grooves := make(map[int]groove)
 
-							notesInRange := make([]Note, len(allNotes[i:j]))
-							copy(notesInRange, allNotes[i:j])
+			var compositionGroove *groove
 
-							var targetNotes []Note
+			if grooveEl := xmlDoc.FindElement("//Composition/Groove"); grooveEl != nil {
+				groove, err := extractGroove(grooveEl)
+				if err != nil {
+					return fmt.Errorf("invalid groove: %w", err)
+				}
+				compositionGroove = &groove
+			}
 
-							for k := range notesInRange {
-								if !slices.Contains(target.channels, notesInRange[k].Channel) {
-									continue
-								}
-								if !slices.Contains(target.tracks, notesInRange[k].Track) {
-									continue
-								}
+			for i, channel := range genChannels {
+				if grooveEl := channel.FindElement("Groove"); grooveEl != nil {
+					groove, err := extractGroove(grooveEl)
+					if err != nil {
+						return fmt.Errorf("invalid groove: %w", err)
+					}
+					grooves[i] = groove
+				} else if compositionGroove != nil {
+					grooves[i] = *compositionGroove
+				}
+			}
 
-								allNotesIndex := i + k - len(targetNotes)
-								allNotes = slices.Delete(allNotes, allNotesIndex, allNotesIndex+1)
+			for i, note := range allNotes {
+				if groove, ok := grooves[note.Channel]; ok {
+					allNotes[i] = applyGroove(note, groove, changes)
+				}
+			}
 
-								targetNotes = append(targetNotes, notesInRange[k])
-							}
+			sort.SliceStable(allNotes, func(i int, j int) bool {
+				return allNotes[i].Start < allNotes[j].Start
+			})
 
-							// Convert notes to revoutil notes
+			return nil
+		}(); err != nil {
+			return err
+		}
 
-							currTime := make(map[myKey]float64)
+		if err := func() error {
 
-							var input []revoutil.Note
+			tracks := make(map[myKey][]Note)
 
-							for _, note := range targetNotes {
-								input = append(input, revoutil.Note{
-									Value:    note.Value,
-									Duration: note.Duration,
-									Channel:  note.Channel,
-									Track:    note.Track,
-									IsPause:  note.IsPause,
-								})
+			for _, note := range allNotes {
+				key := myKey{
+					channel: note.Channel,
+					track:   note.Track,
+				}
+				tracks[key] = append(tracks[key], note)
+			}
 
-								if _, ok := currTime[myKey{note.Channel, note.Track}]; !ok {
-									currTime[myKey{note.Channel, note.Track}] = note.Start
-								}
-							}
+			s := smf.New()
+			clock := smf.MetricTicks(96)
+			s.TimeFormat = clock
 
-							sort.SliceStable(input, func(i, j int) bool {
-								if input[i].Channel < input[j].Channel {
-									return true
-								}
-								if input[i].Track < input[j].Track {
-									return true
-								}
-								return false
-							})
-
-							result := func() []revoutil.Note {
-
-								// Try to find a modification with the same path, args, and input.
-								for i, modification := range modifications {
-									if modification.path == path &&
-										slices.Equal(modification.args, args) &&
-										slices.Equal(modification.input, input) {
-										usedModifications = append(usedModifications, i)
-										return modification.output
-									}
-								}
-								var wg sync.WaitGroup
-
-								wg.Add(1)
-
-								modification := newModification(path, args, input, &wg)
-
-								wg.Wait()
-
-								modifications = append(modifications, modification)
-								usedModifications = append(usedModifications, len(modifications)-1)
-
-								return modification.output
-							}()
-
-							for _, note := range result {
-								allNotes = append(allNotes, Note{
-									Value:    note.Value,
-									Start:    currTime[myKey{note.Channel, note.Track}],
-									Duration: note.Duration,
-									Channel:  note.Channel,
-									Track:    note.Track,
-									IsPause:  note.IsPause,
-								})
-								currTime[myKey{note.Channel, note.Track}] += note.Duration
-							}
+			notesToTicks := func(notes float64) uint32 {
+				return uint32(float64(clock.Ticks4th())*notes) * 4
+			}
 
-							sort.SliceStable(allNotes, func(i int, j int) bool {
-								return allNotes[i].Start < allNotes[j].Start
-							})
-						}
-					}
+			if err := func() error {
+				changesTrack := smf.Track{}
 
-					for i := 0; i < len(modifications); i++ {
-						if !slices.Contains(usedModifications, i) {
-							modifications = slices.Delete(modifications, i, i+1)
-						}
-					}
+				addChange := func(deltaTicks uint32, change change) {
+					changesTrack.Add(deltaTicks, smf.MetaMeter(
+						change.meter.Numerator,
+						change.meter.Denominator,
+					))
+					changesTrack.Add(0, smf.MetaTempo(change.tempo))
+				}
 
-					fmt.Println("saved modifications count:", len(modifications))
-				}()
+				for i, change := range changes {
+					if i == 0 {
+						addChange(0, change)
+						continue
+					}
+					deltaNotes := change.noteStart - changes[i-1].noteStart
+					addChange(notesToTicks(deltaNotes), change)
+				}
 
-				func() {
+				changesTrack.Close(0)
 
-					tracks := make(map[myKey][]Note)
+				if err := s.Add(changesTrack); err != nil {
+					return err
+				}
 
-					for _, note := range allNotes {
-						key := myKey{
-							channel: note.Channel,
-							track:   note.Track,
-						}
-						tracks[key] = append(tracks[key], note)
-					}
+				return nil
+			}(); err != nil {
+				return err
+			}
 
-					s := smf.New()
-					clock := smf.MetricTicks(96)
-					s.TimeFormat = clock
+			var instrumentMap = map[string]uint8{
+				"Acoustic Grand Piano":    0,
+				"Bright Acoustic Piano":   1,
+				"Electric Grand Piano":    2,
+				"Honky-tonk Piano":        3,
+				"Electric Piano 1":        4,
+				"Electric Piano 2":        5,
+				"Harpsichord":             6,
+				"Clavinet":                7,
+				"Celesta":                 8,
+				"Glockenspiel":            9,
+				"Music Box":               10,
+				"Vibraphone":              11,
+				"Marimba":                 12,
+				"Xylophone":               13,
+				"Tubular Bells":           14,
+				"Dulcimer":                15,
+				"Drawbar Organ":           16,
+				"Percussive Organ":        17,
+				"Rock Organ":              18,
+				"Church Organ":            19,
+				"Reed Organ":              20,
+				"Accordion":               21,
+				"Harmonica":               22,
+				"Tango Accordion":         23,
+				"Acoustic Guitar (nylon)": 24,
+				"Acoustic Guitar (steel)": 25,
+				"Electric Guitar (jazz)":  26,
+				"Electric Guitar (clean)": 27,
+				"Electric Guitar (muted)": 28,
+				"Overdriven Guitar":       29,
+				"Distortion Guitar":       30,
+				"Guitar Harmonics":        31,
+				"Acoustic Bass":           32,
+				"Electric Bass (finger)":  33,
+				"Electric Bass (pick)":    34,
+				"Fretless Bass":           35,
+				"Slap Bass 1":             36,
+				"Slap Bass 2":             37,
+				"Synth Bass 1":            38,
+				"Synth Bass 2":            39,
+				"Violin":                  40,
+				"Viola":                   41,
+				"Cello":                   42,
+				"Contrabass":              43,
+				"Tremolo Strings":         44,
+				"Pizzicato Strings":       45,
+				"Orchestral Harp":         46,
+				"Timpani":                 47,
+				"String Ensemble 1":       48,
+				"String Ensemble 2":       49,
+				"Synth Strings 1":         50,
+				"Synth Strings 2":         51,
+				"Choir Aahs":              52,
+				"Voice Oohs":              53,
+				"Synth Choir":             54,
+				"Orchestra Hit":           55,
+				"Trumpet":                 56,
+				"Trombone":                57,
+				"Tuba":                    58,
+				"Muted Trumpet":           59,
+				"French Horn":             60,
+				"Brass Section":           61,
+				"Synth Brass 1":           62,
+				"Synth Brass 2":           63,
+				"Soprano Sax":             64,
+				"Alto Sax":                65,
+				"Tenor Sax":               66,
+				"Baritone Sax":            67,
+				"Oboe":                    68,
+				"English Horn":            69,
+				"Bassoon":                 70,
+				"Clarinet":                71,
+				"Piccolo":                 72,
+				"Flute":                   73,
+				"Recorder":                74,
+				"Pan Flute":               75,
+				"Blown Bottle":            76,
+				"Shakuhachi":              77,
+				"Whistle":                 78,
+				"Ocarina":                 79,
+				"Lead 1 (square)":         80,
+				"Lead 2 (sawtooth)":       81,
+				"Lead 3 (calliope)":       82,
+				"Lead 4 (chiff)":          83,
+				"Lead 5 (charang)":        84,
+				"Lead 6 (voice)":          85,
+				"Lead 7 (fifths)":         86,
+				"Lead 8 (bass + lead)":    87,
+				"Pad 1 (new age)":         88,
+				"Pad 2 (warm)":            89,
+				"Pad 3 (polysynth)":       90,
+				"Pad 4 (choir)":           91,
+				"Pad 5 (bowed)":           92,
+				"Pad 6 (metallic)":        93,
+				"Pad 7 (halo)":            94,
+				"Pad 8 (sweep)":           95,
+				"FX 1 (rain)":             96,
+				"FX 2 (soundtrack)":       97,
+				"FX 3 (crystal)":          98,
+				"FX 4 (atmosphere)":       99,
+				"FX 5 (brightness)":       100,
+				"FX 6 (goblins)":          101,
+				"FX 7 (echoes)":           102,
+				"FX 8 (sci-fi)":           103,
+				"Sitar":                   104,
+				"Banjo":                   105,
+				"Shamisen":                106,
+				"Koto":                    107,
+				"Kalimba":                 108,
+				"Bagpipe":                 109,
+				"Fiddle":                  110,
+				"Shanai":                  111,
+				"Tinkle Bell":             112,
+				"Agogo":                   113,
+				"Steel Drums":             114,
+				"Woodblock":               115,
+				"Taiko Drum":              116,
+				"Melodic Tom":             117,
+				"Synth Drum":              118,
+				"Reverse Cymbal":          119,
+				"Guitar Fret Noise":       120,
+				"Breath Noise":            121,
+				"Seashore":                122,
+				"Bird Tweet":              123,
+				"Telephone Ring":          124,
+				"Helicopter":              125,
+				"Applause":                126,
+				"Gunshot":                 127,
+			}
 
-					notesToTicks := func(notes float64) uint32 {
-						return uint32(float64(clock.Ticks4th())*notes) * 4
-					}
+			keys := maps.Keys(tracks)
 
-					func() {
-						changesTrack := smf.Track{}
+			sort.Slice(keys, func(i, j int) bool {
+				if keys[i].channel < keys[j].channel {
+					return true
+				}
+				if keys[i].channel > keys[j].channel {
+					return false
+				}
+				return keys[i].track < keys[j].track
+			})
 
-						addChange := func(deltaTicks uint32, change change) {
-							changesTrack.Add(deltaTicks, smf.MetaMeter(
-								change.meter.Numerator,
-								change.meter.Denominator,
-							))
-							changesTrack.Add(0, smf.MetaTempo(change.tempo))
-						}
+			for _, key := range keys {
 
-						for i, change := range changes {
-							if i == 0 {
-								addChange(0, change)
-								continue
-							}
-							deltaNotes := change.noteStart - changes[i-1].noteStart
-							addChange(notesToTicks(deltaNotes), change)
-						}
+				instrument := genChannels[key.channel].SelectAttrValue("instrument", "Bright Acoustic Piano")
+				program := instrumentMap[instrument]
 
-						changesTrack.Close(0)
+				track := smf.Track{}
 
-						if err := s.Add(changesTrack); err != nil {
-							log.Fatalln(err)
-						}
-					}()
-
-					var instrumentMap = map[string]uint8{
-						"Acoustic Grand Piano":    0,
-						"Bright Acoustic Piano":   1,
-						"Electric Grand Piano":    2,
-						"Honky-tonk Piano":        3,
-						"Electric Piano 1":        4,
-						"Electric Piano 2":        5,
-						"Harpsichord":             6,
-						"Clavinet":                7,
-						"Celesta":                 8,
-						"Glockenspiel":            9,
-						"Music Box":               10,
-						"Vibraphone":              11,
-						"Marimba":                 12,
-						"Xylophone":               13,
-						"Tubular Bells":           14,
-						"Dulcimer":                15,
-						"Drawbar Organ":           16,
-						"Percussive Organ":        17,
-						"Rock Organ":              18,
-						"Church Organ":            19,
-						"Reed Organ":              20,
-						"Accordion":               21,
-						"Harmonica":               22,
-						"Tango Accordion":         23,
-						"Acoustic Guitar (nylon)": 24,
-						"Acoustic Guitar (steel)": 25,
-						"Electric Guitar (jazz)":  26,
-						"Electric Guitar (clean)": 27,
-						"Electric Guitar (muted)": 28,
-						"Overdriven Guitar":       29,
-						"Distortion Guitar":       30,
-						"Guitar Harmonics":        31,
-						"Acoustic Bass":           32,
-						"Electric Bass (finger)":  33,
-						"Electric Bass (pick)":    34,
-						"Fretless Bass":           35,
-						"Slap Bass 1":             36,
-						"Slap Bass 2":             37,
-						"Synth Bass 1":            38,
-						"Synth Bass 2":            39,
-						"Violin":                  40,
-						"Viola":                   41,
-						"Cello":                   42,
-						"Contrabass":              43,
-						"Tremolo Strings":         44,
-						"Pizzicato Strings":       45,
-						"Orchestral Harp":         46,
-						"Timpani":                 47,
-						"String Ensemble 1":       48,
-						"String Ensemble 2":       49,
-						"Synth Strings 1":         50,
-						"Synth Strings 2":         51,
-						"Choir Aahs":              52,
-						"Voice Oohs":              53,
-						"Synth Choir":             54,
-						"Orchestra Hit":           55,
-						"Trumpet":                 56,
-						"Trombone":                57,
-						"Tuba":                    58,
-						"Muted Trumpet":           59,
-						"French Horn":             60,
-						"Brass Section":           61,
-						"Synth Brass 1":           62,
-						"Synth Brass 2":           63,
-						"Soprano Sax":             64,
-						"Alto Sax":                65,
-						"Tenor Sax":               66,
-						"Baritone Sax":            67,
-						"Oboe":                    68,
-						"English Horn":            69,
-						"Bassoon":                 70,
-						"Clarinet":                71,
-						"Piccolo":                 72,
-						"Flute":                   73,
-						"Recorder":                74,
-						"Pan Flute":               75,
-						"Blown Bottle":            76,
-						"Shakuhachi":              77,
-						"Whistle":                 78,
-						"Ocarina":                 79,
-						"Lead 1 (square)":         80,
-						"Lead 2 (sawtooth)":       81,
-						"Lead 3 (calliope)":       82,
-						"Lead 4 (chiff)":          83,
-						"Lead 5 (charang)":        84,
-						"Lead 6 (voice)":          85,
-						"Lead 7 (fifths)":         86,
-						"Lead 8 (bass + lead)":    87,
-						"Pad 1 (new age)":         88,
-						"Pad 2 (warm)":            89,
-						"Pad 3 (polysynth)":       90,
-						"Pad 4 (choir)":           91,
-						"Pad 5 (bowed)":           92,
-						"Pad 6 (metallic)":        93,
-						"Pad 7 (halo)":            94,
-						"Pad 8 (sweep)":           95,
-						"FX 1 (rain)":             96,
-						"FX 2 (soundtrack)":       97,
-						"FX 3 (crystal)":          98,
-						"FX 4 (atmosphere)":       99,
-						"FX 5 (brightness)":       100,
-						"FX 6 (goblins)":          101,
-						"FX 7 (echoes)":           102,
-						"FX 8 (sci-fi)":           103,
-						"Sitar":                   104,
-						"Banjo":                   105,
-						"Shamisen":                106,
-						"Koto":                    107,
-						"Kalimba":                 108,
-						"Bagpipe":                 109,
-						"Fiddle":                  110,
-						"Shanai":                  111,
-						"Tinkle Bell":             112,
-						"Agogo":                   113,
-						"Steel Drums":             114,
-						"Woodblock":               115,
-						"Taiko Drum":              116,
-						"Melodic Tom":             117,
-						"Synth Drum":              118,
-						"Reverse Cymbal":          119,
-						"Guitar Fret Noise":       120,
-						"Breath Noise":            121,
-						"Seashore":                122,
-						"Bird Tweet":              123,
-						"Telephone Ring":          124,
-						"Helicopter":              125,
-						"Applause":                126,
-						"Gunshot":                 127,
-					}
+				track.Add(0, midi.ProgramChange(uint8(key.channel), program))
 
-					keys := maps.Keys(tracks)
+				var lastTick uint32
 
-					sort.Slice(keys, func(i, j int) bool {
-						if keys[i].channel < keys[j].channel {
-							return true
-						}
-						if keys[i].channel > keys[j].channel {
-							return false
-						}
-						return keys[i].track < keys[j].track
-					})
+				for _, event := range noteEvents(tracks[key], 4*clock.Ticks4th()) {
+					delta := event.tick - lastTick
+					lastTick = event.tick
 
-					for _, key := range keys {
+					if event.isOn {
+						track.Add(delta, midi.NoteOn(uint8(key.channel), uint8(event.note.Value), event.note.Velocity))
+					} else {
+						track.Add(delta, midi.NoteOff(uint8(key.channel), uint8(event.note.Value)))
+					}
+				}
 
-						instrument := genChannels[key.channel].SelectAttrValue("instrument", "Bright Acoustic Piano")
-						program := instrumentMap[instrument]
+				track.Close(0)
 
-						track := smf.Track{}
+				if err := s.Add(track); err != nil {
+					return err
+				}
+			}
 
-						track.Add(0, midi.ProgramChange(uint8(key.channel), program))
+			var buf bytes.Buffer
+			if _, err := s.WriteTo(&buf); err != nil {
+				return err
+			}
+			if err := os.WriteFile("output.midi", buf.Bytes(), 0777); err != nil {
+				return fmt.Errorf("failed to write MIDI file: %w", err)
+			}
 
-						var pause float64
+			return nil
+		}(); err != nil {
+			return err
+		}
 
-						for _, note := range tracks[key] {
+		end := time.Now()
 
-							if note.IsPause {
-								pause += note.Duration
-								continue
-							}
+		fmt.Println("execution time:", end.Sub(start))
 
-							track.Add(notesToTicks(pause), midi.NoteOn(uint8(key.channel), uint8(note.Value), 64))
-							track.Add(notesToTicks(note.Duration), midi.NoteOff(uint8(key.channel), uint8(note.Value)))
+		newPlayer := exec.Command(`C:\Program Files\MuseScore 4\bin\MuseScore4.exe`, `output.midi`)
+		if err := newPlayer.Start(); err != nil {
+			return fmt.Errorf("failed to start player: %w", err)
+		}
 
-							pause = 0
-						}
+		time.Sleep(3 * time.Second)
 
-						track.Close(0)
+		if player != nil {
+			player.Process.Kill()
+		}
 
-						if err := s.Add(track); err != nil {
-							log.Fatalln(err)
-						}
-					}
+		player = newPlayer
 
-					var buf bytes.Buffer
-					s.WriteTo(&buf)
-					os.WriteFile("output.midi", buf.Bytes(), 0777)
-				}()
+		return nil
+	}
 
-				end := time.Now()
+	go func() {
+		defer func() {
+			for _, g := range generations {
+				g.close()
+			}
+			modifiers.stop()
 
-				fmt.Println("execution time:", end.Sub(start))
+			if player != nil {
+				player.Process.Kill()
+				player.Wait()
+			}
 
-				newPlayer := exec.Command(`C:\Program Files\MuseScore 4\bin\MuseScore4.exe`, `output.midi`)
-				if err := newPlayer.Start(); err != nil {
-					log.Fatalln(err)
-				}
+			close(stopped)
+		}()
 
-				time.Sleep(3 * time.Second)
+		for {
+			select {
+			case event := <-w.Event:
+				fmt.Println(event) // Print the event's info.
 
-				if player != nil {
-					player.Process.Kill()
+				if err := render(event.Path); err != nil {
+					fmt.Println("Failed to render:", err)
 				}
 
-				player = newPlayer
-
 			case err := <-w.Error:
-				log.Fatalln(err)
+				fmt.Println("Failed to watch project:", err)
 			case <-w.Closed:
 				return
 			}
@@ -957,9 +1269,18 @@ func Interpret(dir string) error {
 		fmt.Printf("%s: %s\n", path, f.Name())
 	}
 
+	go func() {
+		<-ctx.Done()
+		// Close only fails to stop a watcher that has not started yet.
+		w.Wait()
+		w.Close()
+	}()
+
 	if err := w.Start(100 * time.Millisecond); err != nil {
 		return err
 	}
 
+	<-stopped
+
 	return nil
 }
@@ -0,0 +1,29 @@
+package interpret
+
+// Groups the jobs into levels that are run one after the other. The jobs of
+// a level are independent of each other and only depend on jobs of earlier
+// levels. Jobs keep their order within a level.
+func modJobLevels(jobs []modJob) [][]int {
+	var levels [][]int
+
+	levelOf := make([]int, len(jobs))
+
+	for j := range jobs {
+		var level int
+
+		for i := 0; i < j; i++ {
+			if jobs[j].dependsOn(jobs[i]) && levelOf[i] >= level {
+				level = levelOf[i] + 1
+			}
+		}
+
+		levelOf[j] = level
+
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], j)
+	}
+
+	return levels
+}
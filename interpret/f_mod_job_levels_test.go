@@ -0,0 +1,85 @@
+package interpret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModJobLevels(t *testing.T) {
+	// A job on channel and track 0 of the bars between start and end.
+	job := func(start, end float64) modJob {
+		return modJob{
+			target:      target{channels: []int{0}, tracks: []int{0}},
+			start:       start,
+			end:         end,
+			windowStart: start,
+			windowEnd:   end,
+		}
+	}
+
+	onChannel := func(j modJob, channel int) modJob {
+		j.target.channels = []int{channel}
+		return j
+	}
+
+	withWindow := func(j modJob, window float64) modJob {
+		j.windowStart -= window
+		j.windowEnd += window
+		return j
+	}
+
+	withExplicitStarts := func(j modJob) modJob {
+		j.explicitStarts = true
+		return j
+	}
+
+	tests := []struct {
+		name string
+		jobs []modJob
+		want [][]int
+	}{
+		{
+			name: "apart",
+			jobs: []modJob{job(0, 1), job(2, 3), job(4, 5)},
+			want: [][]int{{0, 1, 2}},
+		},
+		{
+			name: "touching",
+			jobs: []modJob{job(0, 1), job(1, 2), job(3, 4)},
+			want: [][]int{{0, 2}, {1}},
+		},
+		{
+			name: "overlapping in turn",
+			jobs: []modJob{job(0, 2), job(1, 3), job(2.5, 4)},
+			want: [][]int{{0}, {1}, {2}},
+		},
+		{
+			name: "other channels",
+			jobs: []modJob{job(0, 2), onChannel(job(0, 2), 1), job(1, 3)},
+			want: [][]int{{0, 1}, {2}},
+		},
+		{
+			name: "overlapping windows",
+			jobs: []modJob{withWindow(job(0, 1), 0.5), job(1.25, 2)},
+			want: [][]int{{0}, {1}},
+		},
+		{
+			name: "explicit starts before a later job",
+			jobs: []modJob{withExplicitStarts(job(0, 1)), job(4, 5), onChannel(job(4, 5), 1)},
+			want: [][]int{{0, 2}, {1}},
+		},
+		{
+			name: "explicit starts after an earlier job",
+			jobs: []modJob{job(0, 1), withExplicitStarts(job(4, 5))},
+			want: [][]int{{0, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := modJobLevels(test.jobs); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("modJobLevels() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
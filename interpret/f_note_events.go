@@ -0,0 +1,39 @@
+package interpret
+
+import (
+	"math"
+	"sort"
+)
+
+// Returns the note on and note off events of the notes in time order, each
+// placed at the tick of the absolute start or end of its note, so that notes
+// may overlap. Pauses and notes too short for a tick have no events. At the
+// same tick, notes are turned off before others are turned on.
+func noteEvents(notes []Note, ticksPerWholeNote uint32) []noteEvent {
+	toTicks := func(time float64) uint32 {
+		return uint32(math.Max(0, math.Round(time*float64(ticksPerWholeNote))))
+	}
+
+	var events []noteEvent
+
+	for _, note := range notes {
+		on := toTicks(note.Start)
+		off := toTicks(note.Start + note.Duration)
+		if note.IsPause || off <= on {
+			continue
+		}
+		events = append(events,
+			noteEvent{tick: on, isOn: true, note: note},
+			noteEvent{tick: off, isOn: false, note: note},
+		)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].tick != events[j].tick {
+			return events[i].tick < events[j].tick
+		}
+		return !events[i].isOn && events[j].isOn
+	})
+
+	return events
+}
@@ -0,0 +1,21 @@
+package interpret
+
+import (
+	"fmt"
+
+	"github.com/beevik/etree"
+)
+
+// Removes the element of a component and its reference in the choice of
+// definitions from the project XSD.
+func removeComponent(xsdDoc *etree.Document, choice *etree.Element, tag string) {
+	element := xsdDoc.FindElement(
+		fmt.Sprintf("//xs:element[@name='%s']", tag),
+	)
+	xsdDoc.Root().RemoveChild(element)
+
+	referenceElement := choice.FindElement(
+		fmt.Sprintf("//xs:element[@ref='%s']", tag),
+	)
+	choice.RemoveChild(referenceElement)
+}
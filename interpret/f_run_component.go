@@ -0,0 +1,79 @@
+package interpret
+
+import (
+	"revolution/protocol"
+	"sync"
+
+	"github.com/davi4046/revoutil"
+)
+
+// The mode of a major key, as a set of pitch classes.
+const majorMode = 0b101010110101
+
+// The changes components are run with outside of a project: 4/4 in C major
+// at 120 beats per minute.
+var defaultChanges = []change{{
+	meter: revoutil.Meter{Numerator: 4, Denominator: 4},
+	tempo: 120,
+	root:  "C",
+	mode:  majorMode,
+}}
+
+// RunGenerator runs the generator at path and returns its notes at the
+// indices from 0 to count, placed one after the other.
+func RunGenerator(path string, args []string, count int) ([]Note, error) {
+	process, err := startComponentProcess(path, args)
+	if err != nil {
+		return nil, err
+	}
+
+	defer process.stop()
+
+	var notes []Note
+	var position float64
+
+	for i := 0; i < count; i++ {
+		degree, duration, err := process.generate(i, contextAt(position, defaultChanges))
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, Note{
+			Value:    degree,
+			Start:    position,
+			Duration: duration,
+		})
+
+		position += duration
+	}
+
+	return notes, nil
+}
+
+// RunModifier passes the notes to the modifier at path and returns its
+// output.
+func RunModifier(path string, args []string, notes []protocol.Note) ([]protocol.Note, error) {
+	pool := newModifierPool()
+	defer pool.stop()
+
+	input := modificationInput{Notes: notes}
+
+	for _, note := range notes {
+		var start float64
+		if note.Start != nil {
+			start = *note.Start
+		}
+		input.Contexts = append(input.Contexts, contextAt(start, defaultChanges))
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	modification, err := newModification(pool, path, args, input, &wg)
+	if err != nil {
+		return nil, err
+	}
+
+	return modification.output, nil
+}
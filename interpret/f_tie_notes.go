@@ -0,0 +1,33 @@
+package interpret
+
+import "math"
+
+// Merges every note marked as tied into the preceding note on the same
+// channel and track, if that note has the same pitch and ends exactly
+// where the tied note starts. The notes must be sorted by start.
+func tieNotes(notes []Note) []Note {
+	type myKey struct{ channel, track int }
+
+	previous := make(map[myKey]int)
+
+	var tied []Note
+
+	for _, note := range notes {
+		key := myKey{note.Channel, note.Track}
+
+		if i, ok := previous[key]; ok && note.Tie && !note.IsPause {
+			prev := &tied[i]
+			if !prev.IsPause && prev.Value == note.Value &&
+				math.Abs(prev.Start+prev.Duration-note.Start) < 1e-9 {
+				prev.Duration += note.Duration
+				continue
+			}
+		}
+
+		note.Tie = false
+		tied = append(tied, note)
+		previous[key] = len(tied) - 1
+	}
+
+	return tied
+}
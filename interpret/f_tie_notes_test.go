@@ -0,0 +1,103 @@
+package interpret
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTieNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes []Note
+		want  []Note
+	}{
+		{
+			name: "tied note is merged into the preceding note",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.5},
+				{Value: 60, Start: 0.5, Duration: 0.25, Tie: true},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 0.75},
+			},
+		},
+		{
+			name: "untied notes are kept",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.5},
+				{Value: 60, Start: 0.5, Duration: 0.25},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 0.5},
+				{Value: 60, Start: 0.5, Duration: 0.25},
+			},
+		},
+		{
+			name: "different pitch",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.5},
+				{Value: 62, Start: 0.5, Duration: 0.25, Tie: true},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 0.5},
+				{Value: 62, Start: 0.5, Duration: 0.25},
+			},
+		},
+		{
+			name: "gap between the notes",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.25},
+				{Value: 60, Start: 0.5, Duration: 0.25, Tie: true},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 0.25},
+				{Value: 60, Start: 0.5, Duration: 0.25},
+			},
+		},
+		{
+			name: "preceding note is a pause",
+			notes: []Note{
+				{Start: 0, Duration: 0.5, IsPause: true},
+				{Value: 60, Start: 0.5, Duration: 0.25, Tie: true},
+			},
+			want: []Note{
+				{Start: 0, Duration: 0.5, IsPause: true},
+				{Value: 60, Start: 0.5, Duration: 0.25},
+			},
+		},
+		{
+			name: "only notes on the same channel and track are tied",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.5, Track: 0},
+				{Value: 60, Start: 0.25, Duration: 0.25, Track: 1},
+				{Value: 60, Start: 0.5, Duration: 0.5, Track: 0, Tie: true},
+				{Value: 60, Start: 0.5, Duration: 0.5, Channel: 1, Track: 1, Tie: true},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 1, Track: 0},
+				{Value: 60, Start: 0.25, Duration: 0.25, Track: 1},
+				{Value: 60, Start: 0.5, Duration: 0.5, Channel: 1, Track: 1},
+			},
+		},
+		{
+			name: "chain of ties",
+			notes: []Note{
+				{Value: 60, Start: 0, Duration: 0.25},
+				{Value: 60, Start: 0.25, Duration: 0.25, Tie: true},
+				{Value: 60, Start: 0.5, Duration: 0.25, Tie: true},
+			},
+			want: []Note{
+				{Value: 60, Start: 0, Duration: 0.75},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := tieNotes(test.notes)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("tieNotes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
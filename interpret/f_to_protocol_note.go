@@ -0,0 +1,16 @@
+package interpret
+
+import "revolution/protocol"
+
+func toProtocolNote(note Note) protocol.Note {
+	start := note.Start
+
+	return protocol.Note{
+		Value:    note.Value,
+		Start:    &start,
+		Duration: note.Duration,
+		Channel:  note.Channel,
+		Track:    note.Track,
+		IsPause:  note.IsPause,
+	}
+}
@@ -0,0 +1,28 @@
+package interpret
+
+import (
+	"fmt"
+
+	"github.com/beevik/etree"
+)
+
+// Returns the attributes of a component element in the project, followed by
+// the defaults declared in the XSD for the optional parameters it leaves out.
+func withDefaults(xsdDoc *etree.Document, el *etree.Element) []etree.Attr {
+	attributes := append([]etree.Attr{}, el.Attr...)
+
+	declared := xsdDoc.FindElements(
+		fmt.Sprintf("//xs:element[@name='%s']/xs:complexType/xs:attribute", el.Tag),
+	)
+
+	for _, attribute := range declared {
+		name := attribute.SelectAttrValue("name", "")
+		defaultAttr := attribute.SelectAttr("default")
+		if defaultAttr == nil || el.SelectAttr(name) != nil {
+			continue
+		}
+		attributes = append(attributes, etree.Attr{Key: name, Value: defaultAttr.Value})
+	}
+
+	return attributes
+}
@@ -0,0 +1,170 @@
+// Fakecomp is a generator that misbehaves on purpose. It is used by the
+// tests of the interpreter to check how component processes are supervised.
+//
+// To try it in a project, build it with
+//
+//	go build -o fake.revocomp ./interpret/fakecomp
+//
+// and reference it from a project, passing the behavior as the behavior
+// attribute and optionally the number of requests to answer correctly
+// before misbehaving as the requests attribute:
+//
+//	ok       answer every request
+//	hang     stop answering
+//	crash    write to stderr and exit with a non-zero status
+//	exit     exit silently with a zero status
+//	garbage  answer with a line that is not a message
+//	legacy   speak the legacy line protocol
+//	deaf     speak the legacy line protocol, ignoring lines that are not an
+//	         index, such as the hello message
+//	v1       speak version 1 of the protocol, which takes the values of the
+//	         arguments alone, ordered by name
+//	context  announce the range and context capabilities, answering with
+//	         the index as the degree if the context is given and -1 if not
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"revolution/protocol"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	var behavior string
+	var requests int
+	flag.StringVar(&behavior, "behavior", "", "how to misbehave")
+	flag.IntVar(&requests, "requests", 0, "number of requests to answer correctly first")
+	flag.Parse()
+
+	// Components before version 2 are passed the values alone.
+	isPositional := flag.NArg() > 0
+	if isPositional {
+		behavior = flag.Arg(0)
+		if flag.NArg() > 1 {
+			requests, _ = strconv.Atoi(flag.Arg(1))
+		}
+	}
+
+	if behavior == "v1" && !isPositional {
+		fmt.Fprintln(os.Stderr, "expected the values of the arguments alone")
+		os.Exit(1)
+	}
+
+	if behavior == "" {
+		fmt.Fprintln(os.Stderr, "usage: fakecomp --behavior=behavior [--requests=n]")
+		os.Exit(2)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+
+	if behavior == "legacy" || behavior == "deaf" {
+		for scanner.Scan() {
+			index, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+			if err != nil && behavior == "deaf" {
+				continue
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			fmt.Println(index, 0.25)
+		}
+		return
+	}
+
+	for scanner.Scan() {
+		var header protocol.Header
+		if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if header.Type == protocol.TypeHello {
+			version := protocol.Version
+			if behavior == "v1" {
+				version = protocol.PositionalVersion
+			}
+			capabilities := []string{protocol.CapabilityGenerate}
+			if behavior == "context" {
+				capabilities = append(capabilities, protocol.CapabilityRange, protocol.CapabilityContext)
+			}
+			encoder.Encode(protocol.Hello{
+				Type:         protocol.TypeHello,
+				Version:      version,
+				Capabilities: capabilities,
+			})
+			continue
+		}
+
+		if header.Type == protocol.TypeRange {
+			var request protocol.Range
+			if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			// Ranges carry no context.
+			for i := 0; i < request.Count; i++ {
+				encoder.Encode(protocol.Generated{
+					Type:     protocol.TypeGenerated,
+					Index:    request.Start + i*request.Step,
+					Degree:   -1,
+					Duration: 0.25,
+				})
+			}
+			encoder.Encode(protocol.Done{Type: protocol.TypeDone})
+			continue
+		}
+
+		var request protocol.Generate
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if behavior == "context" {
+			degree := -1
+			if request.Context != nil {
+				degree = request.Index
+			}
+			encoder.Encode(protocol.Generated{
+				Type:     protocol.TypeGenerated,
+				Index:    request.Index,
+				Degree:   degree,
+				Duration: 0.25,
+			})
+			continue
+		}
+
+		if requests > 0 || behavior == "ok" || behavior == "v1" {
+			requests--
+			encoder.Encode(protocol.Generated{
+				Type:     protocol.TypeGenerated,
+				Degree:   request.Index,
+				Duration: 0.25,
+			})
+			continue
+		}
+
+		switch behavior {
+		case "hang":
+			time.Sleep(time.Hour)
+		case "crash":
+			fmt.Fprintf(os.Stderr, "panic: failed to generate index %d\n", request.Index)
+			os.Exit(2)
+		case "exit":
+			os.Exit(0)
+		case "garbage":
+			fmt.Println("this is not a message")
+		default:
+			fmt.Fprintln(os.Stderr, "unknown behavior:", behavior)
+			os.Exit(2)
+		}
+	}
+}
@@ -8,4 +8,8 @@ type change struct {
 	key       revoutil.Key
 	meter     revoutil.Meter
 	tempo     float64
+
+	// Root and mode of the key as written in the project.
+	root string
+	mode int
 }
@@ -0,0 +1,30 @@
+package interpret
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A failure of a component process along with the last output the
+// component wrote to stderr.
+type componentError struct {
+	path   string
+	err    error
+	stderr string
+}
+
+func (e componentError) Error() string {
+	message := fmt.Sprintf("component %s: %v", filepath.Base(e.path), e.err)
+
+	stderr := strings.TrimSpace(e.stderr)
+	if stderr == "" {
+		return message
+	}
+
+	return message + "\n\t" + strings.ReplaceAll(stderr, "\n", "\n\t")
+}
+
+func (e componentError) Unwrap() error {
+	return e.err
+}
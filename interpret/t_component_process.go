@@ -0,0 +1,567 @@
+package interpret
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"revolution/protocol"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davi4046/revoutil"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+// The protocol versions of components, identified by path and modification
+// time so that a recompiled component is asked again. Legacy components,
+// which do not answer the hello message, have version 0.
+var componentVersions sync.Map
+
+type componentKey struct {
+	path    string
+	modTime time.Time
+}
+
+var (
+	errExited   = errors.New("exited unexpectedly")
+	errNoAnswer = errors.New("no answer")
+)
+
+// A running generator or modifier.
+type componentProcess struct {
+	path    string
+	command *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *tailBuffer
+
+	// Lines written to stdout. Closed when stdout is closed.
+	lines chan []byte
+	// Closed when the process is stopped by the interpreter.
+	stopped chan struct{}
+	// Closed when the process has exited.
+	exited chan struct{}
+	// The error returned by waiting for the process.
+	waitErr error
+
+	// How long to wait for an answer to a request.
+	timeout time.Duration
+
+	// Whether the component predates the JSON protocol and speaks the
+	// legacy line protocol instead.
+	legacy bool
+	// The hello message of the component. Empty for legacy components.
+	hello protocol.Hello
+}
+
+// Starts the component at path with args of the form --name=value. Older
+// components, which take the values alone, are passed those instead.
+func startComponentProcess(path string, args []string) (*componentProcess, error) {
+	var key componentKey
+
+	if stat, err := os.Stat(path); err == nil {
+		key = componentKey{path, stat.ModTime()}
+	}
+
+	if version, ok := componentVersions.Load(key); ok {
+		switch version {
+		case 0:
+			return launchComponentProcess(path, positionalArgs(args), true)
+		case protocol.PositionalVersion:
+			return greetComponentProcess(path, positionalArgs(args), protocol.PositionalVersion)
+		default:
+			return greetComponentProcess(path, args, protocol.Version)
+		}
+	}
+
+	p, err := greetComponentProcess(path, args, protocol.Version)
+	if err == nil {
+		componentVersions.Store(key, protocol.Version)
+		return p, nil
+	}
+
+	if errors.Is(err, errNoAnswer) {
+		// Legacy components may ignore the hello message instead of
+		// exiting on it.
+		componentVersions.Store(key, 0)
+		return launchComponentProcess(path, positionalArgs(args), true)
+	}
+
+	var versionErr versionError
+	isOlder := errors.As(err, &versionErr) && versionErr.version == protocol.PositionalVersion
+	if !isOlder && !errors.Is(err, errExited) {
+		return nil, err
+	}
+
+	// Older components may exit on args they don't understand, so the
+	// first error is kept in case the component turns out to be current.
+	namedErr := err
+
+	p, err = greetComponentProcess(path, positionalArgs(args), protocol.PositionalVersion)
+	if errors.Is(err, errExited) {
+		// Legacy components exit on the unexpected hello message.
+		componentVersions.Store(key, 0)
+		return launchComponentProcess(path, positionalArgs(args), true)
+	}
+	if errors.As(err, &versionErr) && versionErr.version == protocol.Version {
+		// The component exited for another reason than its args.
+		return nil, namedErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	componentVersions.Store(key, protocol.PositionalVersion)
+
+	return p, nil
+}
+
+// Launches the component and exchanges hello messages with it, expecting it
+// to speak the version. The process is stopped if this fails.
+func greetComponentProcess(path string, args []string, version int) (*componentProcess, error) {
+	p, err := launchComponentProcess(path, args, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.send(protocol.Hello{
+		Type:    protocol.TypeHello,
+		Version: protocol.Version,
+	})
+	if err != nil {
+		p.stop()
+		return nil, err
+	}
+
+	if err := p.receive(protocol.TypeHello, &p.hello); err != nil {
+		p.stop()
+		return nil, err
+	}
+
+	if p.hello.Version != version {
+		p.stop()
+		return nil, p.error(versionError{version: p.hello.Version, expected: version})
+	}
+
+	return p, nil
+}
+
+// Returns the values of args of the form --name=value, which keep their
+// order.
+func positionalArgs(args []string) []string {
+	var values []string
+
+	for _, arg := range args {
+		_, value, _ := strings.Cut(arg, "=")
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func launchComponentProcess(path string, args []string, legacy bool) (*componentProcess, error) {
+	command := exec.Command(path, args...)
+
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stderr := newTailBuffer(4096)
+	command.Stderr = stderr
+
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+
+	timeout := viper.GetDuration("component_timeout")
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	p := &componentProcess{
+		path:    path,
+		command: command,
+		stdin:   stdin,
+		stderr:  stderr,
+		lines:   make(chan []byte),
+		stopped: make(chan struct{}),
+		exited:  make(chan struct{}),
+		timeout: timeout,
+		legacy:  legacy,
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, 16*1024*1024)
+
+		for scanner.Scan() {
+			line := slices.Clone(scanner.Bytes())
+			select {
+			case p.lines <- line:
+			case <-p.stopped:
+				// Keep reading until the process has exited.
+			}
+		}
+
+		close(p.lines)
+
+		p.waitErr = command.Wait()
+		close(p.exited)
+	}()
+
+	return p, nil
+}
+
+func (p *componentProcess) send(message any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return p.write(string(data) + "\n")
+}
+
+func (p *componentProcess) write(s string) error {
+	if _, err := io.WriteString(p.stdin, s); err != nil {
+		// The write usually fails because the component is exiting, which
+		// is reported with its stderr once it has.
+		select {
+		case <-p.exited:
+			return p.exitError()
+		case <-time.After(time.Second):
+			return p.error(err)
+		}
+	}
+	return nil
+}
+
+// Reads the next line written by the component, waiting no longer than the
+// timeout of the process.
+func (p *componentProcess) readLine() ([]byte, error) {
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return nil, p.exitError()
+		}
+		return line, nil
+	case <-timer.C:
+		return nil, p.error(fmt.Errorf("%w within %s", errNoAnswer, p.timeout))
+	}
+}
+
+func (p *componentProcess) receive(wantedType string, message any) error {
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	if err := protocol.Decode(line, wantedType, message); err != nil {
+		return p.error(err)
+	}
+	return nil
+}
+
+func (p *componentProcess) exitError() error {
+	<-p.exited
+	if p.waitErr != nil {
+		return p.error(fmt.Errorf("%w: %v", errExited, p.waitErr))
+	}
+	return p.error(errExited)
+}
+
+func (p *componentProcess) error(err error) error {
+	return componentError{
+		path:   p.path,
+		err:    err,
+		stderr: p.stderr.String(),
+	}
+}
+
+// Stops the process if it is still running.
+func (p *componentProcess) stop() {
+	select {
+	case <-p.stopped:
+		return
+	default:
+		close(p.stopped)
+	}
+
+	p.stdin.Close()
+
+	select {
+	case <-p.exited:
+	case <-time.After(time.Second):
+		// Give the component a moment to exit on its own before killing it.
+		p.command.Process.Kill()
+		<-p.exited
+	}
+}
+
+// Requests the note at the index. The context is only sent to generators
+// with the context capability.
+func (p *componentProcess) generate(index int, ctx protocol.Context) (int, float64, error) {
+	if p.legacy {
+		return p.generateLegacy(index)
+	}
+
+	request := protocol.Generate{
+		Type:  protocol.TypeGenerate,
+		Index: index,
+	}
+
+	if p.hello.HasCapability(protocol.CapabilityContext) {
+		request.Context = &ctx
+	}
+
+	err := p.send(request)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var generated protocol.Generated
+	if err := p.receive(protocol.TypeGenerated, &generated); err != nil {
+		return 0, 0, err
+	}
+
+	return generated.Degree, generated.Duration, nil
+}
+
+// Requests count notes beginning at start and moving by step, and passes
+// each of them to yield until yield returns false.
+func (p *componentProcess) generateRange(start, count, step int, yield func(index, degree int, duration float64) bool) error {
+	err := p.send(protocol.Range{
+		Type:  protocol.TypeRange,
+		Start: start,
+		Count: count,
+		Step:  step,
+	})
+	if err != nil {
+		return err
+	}
+
+	var isStopped bool
+
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+
+		var header protocol.Header
+		if err := json.Unmarshal(line, &header); err == nil && header.Type == protocol.TypeDone {
+			return nil
+		}
+
+		var generated protocol.Generated
+		if err := protocol.Decode(line, protocol.TypeGenerated, &generated); err != nil {
+			return p.error(err)
+		}
+
+		if isStopped {
+			// Discard the notes generated before the stop arrived.
+			continue
+		}
+
+		if !yield(generated.Index, generated.Degree, generated.Duration) {
+			if err := p.send(protocol.Stop{Type: protocol.TypeStop}); err != nil {
+				return err
+			}
+			isStopped = true
+		}
+	}
+}
+
+func (p *componentProcess) generateLegacy(index int) (int, float64, error) {
+	if err := p.write(fmt.Sprintf("%d\n", index)); err != nil {
+		return 0, 0, err
+	}
+
+	data, err := p.readLine()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	line := string(data)
+
+	degreeStr, durationStr, ok := strings.Cut(line, " ")
+	if !ok {
+		return 0, 0, p.error(fmt.Errorf("invalid generator output: %s", line))
+	}
+
+	degree, err := strconv.Atoi(degreeStr)
+	if err != nil {
+		return 0, 0, p.error(err)
+	}
+
+	duration, err := strconv.ParseFloat(durationStr, 64)
+	if err != nil {
+		return 0, 0, p.error(err)
+	}
+
+	return degree, duration, nil
+}
+
+// Passes the notes surrounding the modified range to modifiers with the
+// context capability. Other modifiers are not told about them.
+func (p *componentProcess) window(before, after []protocol.Note) error {
+	if p.legacy || !p.hello.HasCapability(protocol.CapabilityContext) {
+		return nil
+	}
+
+	return p.send(protocol.Window{
+		Type:   protocol.TypeWindow,
+		Before: before,
+		After:  after,
+	})
+}
+
+// Passes the note to the modifier and returns its output. The context is
+// only sent to modifiers with the context capability.
+func (p *componentProcess) modify(note protocol.Note, ctx protocol.Context) ([]protocol.Note, error) {
+	if p.legacy {
+		legacyNote := revoutil.Note{
+			Value:    note.Value,
+			Duration: note.Duration,
+			Channel:  note.Channel,
+			Track:    note.Track,
+			IsPause:  note.IsPause,
+		}
+		if err := p.write(fmt.Sprintf("%v\n", legacyNote)); err != nil {
+			return nil, err
+		}
+		return p.receiveLegacyNotes()
+	}
+
+	request := protocol.Modify{
+		Type: protocol.TypeModify,
+		Note: note,
+	}
+
+	if p.hello.HasCapability(protocol.CapabilityContext) {
+		request.Context = &ctx
+	}
+
+	if err := p.send(request); err != nil {
+		return nil, err
+	}
+
+	return p.receiveNotes()
+}
+
+func (p *componentProcess) finish() ([]protocol.Note, error) {
+	if p.legacy {
+		// Modifiers without a Finish method exit without an answer.
+		if err := p.write("finish\n"); err != nil {
+			return nil, nil
+		}
+		line, err := p.readLine()
+		if errors.Is(err, errExited) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes, err := parseLegacyNotes(string(line))
+		if err != nil {
+			return nil, p.error(err)
+		}
+		return notes, nil
+	}
+
+	if !p.hello.HasCapability(protocol.CapabilityFinish) {
+		return nil, nil
+	}
+
+	if err := p.send(protocol.Finish{Type: protocol.TypeFinish}); err != nil {
+		return nil, err
+	}
+
+	return p.receiveNotes()
+}
+
+func (p *componentProcess) receiveNotes() ([]protocol.Note, error) {
+	var modified protocol.Modified
+	if err := p.receive(protocol.TypeModified, &modified); err != nil {
+		return nil, err
+	}
+	return modified.Notes, nil
+}
+
+func (p *componentProcess) receiveLegacyNotes() ([]protocol.Note, error) {
+	line, err := p.readLine()
+	if err != nil {
+		return nil, err
+	}
+	notes, err := parseLegacyNotes(string(line))
+	if err != nil {
+		return nil, p.error(err)
+	}
+	return notes, nil
+}
+
+// Parses a slice of notes printed with the %v verb.
+func parseLegacyNotes(line string) ([]protocol.Note, error) {
+	var notes []protocol.Note
+
+	line = strings.Trim(line, "[{}]")
+
+	if line == "" {
+		return notes, nil
+	}
+
+	for _, s := range strings.Split(line, "} {") {
+		parts := strings.Split(s, " ")
+
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("invalid modifier output: %s", line)
+		}
+
+		value, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		duration, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		channel, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		track, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return nil, err
+		}
+
+		isPause, err := strconv.ParseBool(parts[4])
+		if err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, protocol.Note{
+			Value:    value,
+			Duration: duration,
+			Channel:  channel,
+			Track:    track,
+			IsPause:  isPause,
+		})
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,253 @@
+package interpret
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"revolution/protocol"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// The directory fakecomp is built into.
+var fakecompDir string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "fakecomp")
+	if err != nil {
+		panic(err)
+	}
+	fakecompDir = dir
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var (
+	fakecompOnce sync.Once
+	fakecompErr  error
+)
+
+// Builds fakecomp once for all tests and returns the path to a copy of its
+// binary named name, as components are told apart by their path.
+func buildFakecomp(t *testing.T, name string) string {
+	t.Helper()
+
+	built := filepath.Join(fakecompDir, "fakecomp")
+
+	fakecompOnce.Do(func() {
+		output, err := exec.Command("go", "build", "-o", built, "./fakecomp").CombinedOutput()
+		if err != nil {
+			fakecompErr = errors.New(string(output))
+		}
+	})
+
+	if fakecompErr != nil {
+		t.Fatalf("failed to build fakecomp: %v", fakecompErr)
+	}
+
+	data, err := os.ReadFile(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), name+".revocomp")
+	if err := os.WriteFile(path, data, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func withTimeout(t *testing.T, timeout time.Duration) {
+	t.Helper()
+
+	previous := viper.Get("component_timeout")
+	viper.Set("component_timeout", timeout)
+	t.Cleanup(func() { viper.Set("component_timeout", previous) })
+}
+
+func startFakecomp(t *testing.T, args ...string) *componentProcess {
+	t.Helper()
+
+	p, err := startComponentProcess(buildFakecomp(t, "fake"), args)
+	if err != nil {
+		t.Fatalf("startComponentProcess() error = %v", err)
+	}
+	t.Cleanup(p.stop)
+
+	return p
+}
+
+func TestComponentProcessAnswers(t *testing.T) {
+	p := startFakecomp(t, "--behavior=ok")
+
+	for i := 0; i < 3; i++ {
+		degree, duration, err := p.generate(i, contextAt(0, defaultChanges))
+		if err != nil {
+			t.Fatalf("generate(%d) error = %v", i, err)
+		}
+		if degree != i || duration != 0.25 {
+			t.Errorf("generate(%d) = %d, %v, want %d, 0.25", i, degree, duration, i)
+		}
+	}
+}
+
+func TestComponentProcessTimeout(t *testing.T) {
+	withTimeout(t, 200*time.Millisecond)
+
+	p := startFakecomp(t, "--behavior=hang", "--requests=1")
+
+	if _, _, err := p.generate(0, contextAt(0, defaultChanges)); err != nil {
+		t.Fatalf("generate(0) error = %v", err)
+	}
+
+	begin := time.Now()
+
+	_, _, err := p.generate(1, contextAt(0, defaultChanges))
+	if err == nil || !strings.Contains(err.Error(), "no answer within 200ms") {
+		t.Fatalf("generate(1) error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("generate(1) returned after %s", elapsed)
+	}
+}
+
+func TestComponentProcessStderr(t *testing.T) {
+	p := startFakecomp(t, "--behavior=crash")
+
+	_, _, err := p.generate(7, contextAt(0, defaultChanges))
+	if !errors.Is(err, errExited) {
+		t.Fatalf("generate(7) error = %v, want %v", err, errExited)
+	}
+	if !strings.Contains(err.Error(), "panic: failed to generate index 7") {
+		t.Errorf("error does not include the stderr of the component: %v", err)
+	}
+}
+
+func TestComponentProcessExit(t *testing.T) {
+	p := startFakecomp(t, "--behavior=exit")
+
+	_, _, err := p.generate(0, contextAt(0, defaultChanges))
+	if !errors.Is(err, errExited) {
+		t.Fatalf("generate(0) error = %v, want %v", err, errExited)
+	}
+}
+
+func TestComponentProcessGarbage(t *testing.T) {
+	p := startFakecomp(t, "--behavior=garbage")
+
+	_, _, err := p.generate(0, contextAt(0, defaultChanges))
+	if err == nil || !strings.Contains(err.Error(), "invalid message: this is not a message") {
+		t.Fatalf("generate(0) error = %v, want an invalid message", err)
+	}
+}
+
+func TestComponentProcessLegacy(t *testing.T) {
+	p := startFakecomp(t, "--behavior=legacy")
+
+	if !p.legacy {
+		t.Fatal("component was not relaunched as a legacy component")
+	}
+
+	degree, duration, err := p.generate(3, contextAt(0, defaultChanges))
+	if err != nil {
+		t.Fatalf("generate(3) error = %v", err)
+	}
+	if degree != 3 || duration != 0.25 {
+		t.Errorf("generate(3) = %d, %v, want 3, 0.25", degree, duration)
+	}
+
+	stat, err := os.Stat(p.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := componentVersions.Load(componentKey{p.path, stat.ModTime()}); version != 0 {
+		t.Errorf("component is remembered as version %v, want 0", version)
+	}
+}
+
+func TestComponentProcessLegacyIgnoringHello(t *testing.T) {
+	withTimeout(t, 200*time.Millisecond)
+
+	path := buildFakecomp(t, "deaf")
+
+	begin := time.Now()
+
+	p, err := startComponentProcess(path, []string{"--behavior=deaf"})
+	if err != nil {
+		t.Fatalf("startComponentProcess() error = %v", err)
+	}
+	defer p.stop()
+
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("startComponentProcess() returned after %s", elapsed)
+	}
+
+	if !p.legacy {
+		t.Fatal("component was not relaunched as a legacy component")
+	}
+
+	degree, duration, err := p.generate(3, contextAt(0, defaultChanges))
+	if err != nil {
+		t.Fatalf("generate(3) error = %v", err)
+	}
+	if degree != 3 || duration != 0.25 {
+		t.Errorf("generate(3) = %d, %v, want 3, 0.25", degree, duration)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := componentVersions.Load(componentKey{path, stat.ModTime()}); version != 0 {
+		t.Errorf("component is remembered as version %v, want 0", version)
+	}
+}
+
+func TestComponentProcessPositionalArgs(t *testing.T) {
+	path := buildFakecomp(t, "v1")
+
+	for i := 0; i < 2; i++ {
+		p, err := startComponentProcess(path, []string{"--behavior=v1", "--requests=0"})
+		if err != nil {
+			t.Fatalf("startComponentProcess() error = %v", err)
+		}
+		defer p.stop()
+
+		if p.legacy || p.hello.Version != protocol.PositionalVersion {
+			t.Fatalf("component speaks version %d, want %d", p.hello.Version, protocol.PositionalVersion)
+		}
+
+		if _, _, err := p.generate(0, contextAt(0, defaultChanges)); err != nil {
+			t.Fatalf("generate(0) error = %v", err)
+		}
+	}
+}
+
+func TestComponentProcessExitsBeforeHello(t *testing.T) {
+	path := buildFakecomp(t, "usage")
+
+	// Without a behavior, fakecomp exits before the hello. Passed the values
+	// alone, it takes the first for the behavior and answers the hello, so
+	// it is not mistaken for an older component.
+	_, err := startComponentProcess(path, []string{"--requests=1"})
+	if !errors.Is(err, errExited) || !strings.Contains(err.Error(), "usage: fakecomp") {
+		t.Fatalf("startComponentProcess() error = %v, want the usage", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, ok := componentVersions.Load(componentKey{path, stat.ModTime()}); ok {
+		t.Errorf("component is remembered as version %v", version)
+	}
+}
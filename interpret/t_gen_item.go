@@ -19,4 +19,9 @@ type genItem struct {
 
 	add int
 	sub int
+
+	// Whether the first note is tied to the last note of the previous item.
+	tie bool
+	// What happens to notes that overrun the end of the item.
+	overrun overrun
 }
@@ -1,14 +1,11 @@
 package interpret
 
 import (
-	"bufio"
 	"fmt"
-	"io"
-	"log"
-	"math"
-	"os/exec"
-	"strconv"
-	"strings"
+	"reflect"
+	"revolution/builtin"
+	"revolution/cache"
+	"revolution/protocol"
 	"sync"
 
 	"golang.org/x/exp/slices"
@@ -16,147 +13,307 @@ import (
 
 type generationManager struct {
 	settings   generationSettings
-	command    *exec.Cmd
-	stdin      *io.WriteCloser
-	stdout     *io.ReadCloser
+	cache      *cache.Cache
+	process    *componentProcess
 	generation []Note
+
+	// The generator if it is built in, in which case there is no process.
+	builtin builtin.Generator
+
+	// Generations of generators no longer in use, shared by all managers.
+	recent *lruCache[string, cache.Generation]
+
+	// Notes generated from index 0 onwards, starting at 0.
+	positive []Note
+	// Notes generated from index -1 backwards, ending at 0. The note of
+	// index -1 comes first.
+	negative []Note
+
+	// Key of the generation in the cache.
+	cacheKey string
+	// Results of the generator by index.
+	cached cache.Generation
+	// Whether cached holds results that are not stored in the cache yet.
+	isDirty bool
+	// Whether the generator failed during the previous render.
+	hasFailed bool
 }
 
 func (g *generationManager) update(settings generationSettings, wg *sync.WaitGroup) {
+	defer wg.Done()
+
 	hasPathChanged := settings.path != g.settings.path
 	hasArgsChanged := !slices.Equal(settings.args, g.settings.args)
 	hasStartChanged := settings.start != g.settings.start
 	hasEndChanged := settings.end != g.settings.end
+	hasContextChanged := !reflect.DeepEqual(settings.spans, g.settings.spans) ||
+		!reflect.DeepEqual(settings.changes, g.settings.changes)
 
 	g.settings = settings
 
 	if hasPathChanged || hasArgsChanged {
-		wg.Add(1)
-		g.initialize(wg)
-		g.regenerate(wg)
+		g.initialize()
+		g.regenerate()
 		return
 	}
-	if hasStartChanged || hasEndChanged {
-		g.regenerate(wg)
+	if hasContextChanged && g.cached.IsContextual {
+		// Notes whose context is unchanged are still taken from the cache.
+		g.positive = nil
+		g.negative = nil
+		g.regenerate()
 		return
 	}
-
-	wg.Done()
+	if hasStartChanged || hasEndChanged || g.hasFailed {
+		g.regenerate()
+	}
 }
 
-func (g *generationManager) initialize(wg *sync.WaitGroup) {
-	defer wg.Done()
-
+func (g *generationManager) initialize() {
 	fmt.Println("init with command:", g.settings.path, g.settings.args)
 
-	g.command = exec.Command(g.settings.path, g.settings.args...)
+	// The process is started once a result is missing from the cache.
+	g.close()
 
-	stdin, err := g.command.StdinPipe()
-	if err != nil {
-		log.Fatalln(err)
+	g.positive = nil
+	g.negative = nil
+
+	g.cacheKey = ""
+	g.cached = cache.Generation{Notes: make(map[int]cache.GeneratedNote)}
+	g.isDirty = false
+
+	// Built-in generators are cheap to run and are not cached.
+	if g.cache == nil || builtin.IsPath(g.settings.path) {
+		return
 	}
 
-	stdout, err := g.command.StdoutPipe()
+	key, err := g.cache.Key(g.settings.path, g.settings.args)
 	if err != nil {
-		log.Fatalln(err)
+		fmt.Println("Failed to compute cache key:", err)
+		return
 	}
 
-	g.stdin = &stdin
-	g.stdout = &stdout
+	g.cacheKey = key
+
+	if g.recent != nil {
+		if cached, ok := g.recent.take(key); ok {
+			g.cached = cached
+			return
+		}
+	}
 
-	if err := g.command.Start(); err != nil {
-		log.Fatalln(err)
+	cached, err := g.cache.LoadGeneration(key)
+	if err != nil {
+		fmt.Println("Failed to load cached generation:", err)
 	}
+
+	g.cached = cached
 }
 
-func (g *generationManager) regenerate(wg *sync.WaitGroup) {
-	g.generation = g.generateFromTo(g.settings.start, g.settings.end, wg)
+// Stops the process of the generator and keeps its results for when it is
+// used again.
+func (g *generationManager) close() {
+	if g.process != nil {
+		g.process.stop()
+		g.process = nil
+	}
+
+	g.builtin = nil
+
+	if g.recent != nil && g.cacheKey != "" && len(g.cached.Notes) > 0 {
+		g.recent.put(g.cacheKey, g.cached)
+	}
 }
 
-func (g *generationManager) generateFromTo(from float64, to float64, wg *sync.WaitGroup) []Note {
-	wg.Add(1)
+// Brings the generation up to date with the start and end of the settings.
+// Only the indices missing at either end are generated.
+func (g *generationManager) regenerate() {
+	g.hasFailed = false
 
-	negativeGen := g.generate(-1, math.Min(from, 0), wg)
-	positiveGen := g.generate(0, math.Max(to, 0), wg)
+	from := g.settings.start
+	to := g.settings.end
 
-	generation := append(negativeGen, positiveGen...)
+	if err := g.extend(from, to); err != nil {
+		// Keep what has been generated so far and try again on the next
+		// render.
+		fmt.Println("Generation failed:", err)
+		g.hasFailed = true
+	}
 
-	return getFromTo(generation, from, to)
+	g.trim(from, to)
+
+	generation := append(reverse(slices.Clone(g.negative)), g.positive...)
+
+	g.generation = getFromTo(generation, from, to)
+
+	if g.isDirty && g.cacheKey != "" {
+		if err := g.cache.StoreGeneration(g.cacheKey, g.cached); err != nil {
+			fmt.Println("Failed to store generation in cache:", err)
+		}
+		g.isDirty = false
+	}
 }
 
-func (g *generationManager) generate(startIndex int, length float64, wg *sync.WaitGroup) []Note {
-	defer wg.Done()
+// The number of indices requested at once from generators that support
+// ranges. The generator is stopped as soon as enough has been generated.
+const rangeSize = 256
+
+// Returns the result of the generator at the index, whose note is placed
+// at the position within the generation. The generator is only asked if the
+// result is not cached. Generators that support ranges are asked for the
+// following indices in the direction of step as well, until they have
+// generated a duration of remaining. Ranges carry no context, so contextual
+// generators are asked for one index at a time.
+func (g *generationManager) generateIndex(index int, step int, remaining float64, position float64) (int, float64, error) {
+	if builtin.IsPath(g.settings.path) {
+		if g.builtin == nil {
+			generator, err := builtin.New(g.settings.path, g.settings.args)
+			if err != nil {
+				return 0, 0, err
+			}
+			g.builtin = generator
+		}
 
-	var generation []Note
+		degree, duration := g.builtin.Generate(index)
+		return degree, duration, nil
+	}
+
+	ctx := contextAt(compositionPosition(position, g.settings.spans), g.settings.changes)
+
+	if note, ok := g.cached.Notes[index]; ok {
+		if !g.cached.IsContextual || note.Context != nil && *note.Context == ctx {
+			return note.Degree, note.Duration, nil
+		}
+	}
 
-	if length == 0 {
-		return generation
+	if g.process == nil {
+		process, err := startComponentProcess(g.settings.path, g.settings.args)
+		if err != nil {
+			return 0, 0, err
+		}
+		g.process = process
+		g.cached.IsContextual = process.hello.HasCapability(protocol.CapabilityContext)
 	}
 
-	var currLength float64
+	if g.process.hello.HasCapability(protocol.CapabilityRange) && !g.cached.IsContextual {
+		var length float64
 
-	currIndex := startIndex
+		err := g.process.generateRange(index, rangeSize, step, func(i, degree int, duration float64) bool {
+			g.cached.Notes[i] = cache.GeneratedNote{
+				Degree:   degree,
+				Duration: duration,
+			}
+			g.isDirty = true
 
-	writeIndex := func() {
-		_, err := io.WriteString(*g.stdin, fmt.Sprintf("%d\n", currIndex))
+			length += duration
+			return length < remaining
+		})
 		if err != nil {
-			log.Fatalln(err)
+			g.process.stop()
+			g.process = nil
+			return 0, 0, err
 		}
-		if length > 0 {
-			currIndex++
-		} else {
-			currIndex--
+
+		note, ok := g.cached.Notes[index]
+		if !ok {
+			return 0, 0, fmt.Errorf("generator did not generate index %d", index)
 		}
+
+		return note.Degree, note.Duration, nil
 	}
 
-	scanner := bufio.NewScanner(*g.stdout)
+	degree, duration, err := g.process.generate(index, ctx)
+	if err != nil {
+		g.process.stop()
+		g.process = nil
+		return 0, 0, err
+	}
 
-	writeIndex()
+	note := cache.GeneratedNote{
+		Degree:   degree,
+		Duration: duration,
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	if g.cached.IsContextual {
+		note.Context = &ctx
+	}
 
-		degreeStr, durationStr, ok := strings.Cut(line, " ")
-		if !ok {
-			log.Fatalln("Invalid generator output:", line)
+	g.cached.Notes[index] = note
+	g.isDirty = true
+
+	return degree, duration, nil
+}
+
+// Generates notes until the generation covers from and to.
+func (g *generationManager) extend(from float64, to float64) error {
+	for {
+		var end float64
+		if len(g.positive) > 0 {
+			last := g.positive[len(g.positive)-1]
+			end = last.Start + last.Duration
+		}
+		if end >= to {
+			break
 		}
 
-		degree, err := strconv.Atoi(degreeStr)
+		note, err := g.generateNote(len(g.positive), 1, to-end, end)
 		if err != nil {
-			log.Fatalln(err)
+			return err
 		}
+		note.Start = end
 
-		duration, err := strconv.ParseFloat(durationStr, 64)
-		if err != nil {
-			log.Fatalln(err)
+		g.positive = append(g.positive, note)
+	}
+
+	for {
+		var start float64
+		if len(g.negative) > 0 {
+			start = g.negative[len(g.negative)-1].Start
+		}
+		if start <= from {
+			break
 		}
 
-		start := currLength
-		if length < 0 {
-			start -= duration
+		// The start of the note is unknown before it is generated, so
+		// its context is that of its end.
+		note, err := g.generateNote(-len(g.negative)-1, -1, start-from, start)
+		if err != nil {
+			return err
 		}
+		note.Start = start - note.Duration
 
-		generation = append(generation, Note{
-			Value:    degree,
-			Start:    start,
-			Duration: duration,
-		})
+		g.negative = append(g.negative, note)
+	}
 
-		if length > 0 {
-			currLength += duration
-			if currLength >= length {
-				break
-			}
-		} else {
-			currLength -= duration
-			if currLength <= length {
-				generation = reverse(generation)
-				break
-			}
+	return nil
+}
+
+// Removes the notes that lie entirely outside from and to.
+func (g *generationManager) trim(from float64, to float64) {
+	for len(g.positive) > 0 && g.positive[len(g.positive)-1].Start >= to {
+		g.positive = g.positive[:len(g.positive)-1]
+	}
+	for len(g.negative) > 0 {
+		last := g.negative[len(g.negative)-1]
+		if last.Start+last.Duration > from {
+			break
 		}
+		g.negative = g.negative[:len(g.negative)-1]
+	}
+}
+
+func (g *generationManager) generateNote(index int, step int, remaining float64, position float64) (Note, error) {
+	degree, duration, err := g.generateIndex(index, step, remaining, position)
+	if err != nil {
+		return Note{}, err
+	}
 
-		writeIndex()
+	if duration <= 0 {
+		return Note{}, fmt.Errorf("generator returned a duration of %v at index %d", duration, index)
 	}
 
-	return generation
+	return Note{
+		Value:    degree,
+		Duration: duration,
+		Velocity: defaultVelocity,
+	}, nil
 }
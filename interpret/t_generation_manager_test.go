@@ -0,0 +1,56 @@
+package interpret
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerationManagerAsksContextualGeneratorsForEachIndex(t *testing.T) {
+	g := &generationManager{
+		settings: generationSettings{
+			path:    buildFakecomp(t, "context"),
+			args:    []string{"--behavior=context"},
+			start:   0,
+			end:     1,
+			spans:   []generationSpan{{offset: 0, start: 2, length: 1}},
+			changes: defaultChanges,
+		},
+	}
+
+	g.initialize()
+	g.regenerate()
+	t.Cleanup(g.close)
+
+	if g.hasFailed {
+		t.Fatal("generation failed")
+	}
+
+	if !g.cached.IsContextual {
+		t.Fatal("generator is not contextual")
+	}
+
+	for i, note := range g.generation {
+		if note.Value != i {
+			t.Errorf("note %d has degree %d, want %d", i, note.Value, i)
+		}
+	}
+
+	for index, note := range g.cached.Notes {
+		if note.Context == nil {
+			t.Errorf("result of index %d is cached without its context", index)
+		}
+	}
+
+	// Every note is taken from the cache, without the generator.
+	g.close()
+	g.settings.path = filepath.Join(t.TempDir(), "missing.revocomp")
+	g.positive = nil
+	g.regenerate()
+
+	if g.hasFailed {
+		t.Error("generation failed on cached results")
+	}
+	if len(g.generation) != 4 {
+		t.Errorf("generation has %d notes, want 4", len(g.generation))
+	}
+}
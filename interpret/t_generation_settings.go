@@ -5,4 +5,9 @@ type generationSettings struct {
 	args  []string
 	start float64
 	end   float64
+
+	// Where the generation is placed in the composition, in the order of
+	// the items. Used to tell generators the context of their notes.
+	spans   []generationSpan
+	changes []change
 }
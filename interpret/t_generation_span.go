@@ -0,0 +1,11 @@
+package interpret
+
+// A part of a generation placed in the composition by an item.
+type generationSpan struct {
+	// Start of the part within the generation in whole notes.
+	offset float64
+	// Start of the item in the composition in whole notes.
+	start float64
+	// Length of the item in whole notes.
+	length float64
+}
@@ -0,0 +1,10 @@
+package interpret
+
+type groove struct {
+	// Length of a subdivision in whole notes.
+	subdivision float64
+	// Share of each pair of subdivisions given to the first. 0.5 is straight.
+	swing float64
+	// Velocity factors for consecutive subdivisions, repeated throughout each bar.
+	accents []float64
+}
@@ -7,4 +7,8 @@ type modItem struct {
 	barEnd float64
 
 	target string
+
+	// How many bars before and after the item are passed to the modifier
+	// as surrounding notes.
+	window float64
 }
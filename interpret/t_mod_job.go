@@ -0,0 +1,43 @@
+package interpret
+
+import "golang.org/x/exp/slices"
+
+// A mod item together with the modifier that is applied to it.
+type modJob struct {
+	path   string
+	args   []string
+	target target
+
+	// Start and end of the item in whole notes.
+	start float64
+	end   float64
+	// Start and end of the item including its window in whole notes.
+	windowStart float64
+	windowEnd   float64
+
+	// Whether the modifier may place notes at explicit starts, which need
+	// not be within the item.
+	explicitStarts bool
+}
+
+// Whether the job, coming after other, may see or replace notes that other
+// sees or replaces. Ranges that only touch count as overlapping, since the
+// note sounding at the start of an item is modified along with it. Notes
+// placed at explicit starts may land anywhere, so such jobs only depend on
+// the targets.
+func (j modJob) dependsOn(other modJob) bool {
+	if !other.explicitStarts && (j.windowStart > other.windowEnd || other.windowStart > j.windowEnd) {
+		return false
+	}
+	return containsAny(j.target.channels, other.target.channels) &&
+		containsAny(j.target.tracks, other.target.tracks)
+}
+
+func containsAny(a []int, b []int) bool {
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
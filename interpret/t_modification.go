@@ -1,121 +1,91 @@
 package interpret
 
 import (
-	"bufio"
 	"fmt"
-	"io"
-	"log"
-	"os/exec"
-	"strconv"
-	"strings"
+	"revolution/cache"
+	"revolution/protocol"
 	"sync"
-
-	"github.com/davi4046/revoutil"
 )
 
 type modification struct {
 	path   string
 	args   []string
-	input  []revoutil.Note
-	output []revoutil.Note
+	input  modificationInput
+	output []protocol.Note
 }
 
-func newModification(path string, args []string, input []revoutil.Note, wg *sync.WaitGroup) modification {
+func newModification(pool *modifierPool, path string, args []string, input modificationInput, wg *sync.WaitGroup) (modification, error) {
 	defer wg.Done()
 
-	var output []revoutil.Note
-
-	command := exec.Command(path, args...)
+	var output []protocol.Note
 
-	stdin, err := command.StdinPipe()
+	process, err := pool.get(path, args)
 	if err != nil {
-		log.Fatalln(err)
+		return modification{}, err
 	}
 
-	stdout, err := command.StdoutPipe()
-	if err != nil {
-		log.Fatalln(err)
+	if err := process.window(input.Before, input.After); err != nil {
+		process.stop()
+		return modification{}, err
 	}
 
-	if err := command.Start(); err != nil {
-		log.Fatalln(err)
-	}
-
-	scanner := bufio.NewScanner(stdout)
-
-	var isFinishing bool
-	var currIndex int
-
-	sendNote := func() {
-		_, err := io.WriteString(stdin, fmt.Sprintf("%v\n", input[currIndex]))
+	for i, note := range input.Notes {
+		notes, err := process.modify(note, input.Contexts[i])
 		if err != nil {
-			log.Fatalln(err)
+			process.stop()
+			return modification{}, err
 		}
-		currIndex++
+		output = append(output, notes...)
 	}
 
-	sendNote()
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		line = strings.Trim(line, "[{}]")
-
-		if line != "" {
-			parts := strings.Split(line, "} {")
-
-			for _, s := range parts {
-				parts := strings.Split(s, " ")
-
-				value, err := strconv.Atoi(parts[0])
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				duration, err := strconv.ParseFloat(parts[1], 64)
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				channel, err := strconv.Atoi(parts[2])
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				track, err := strconv.Atoi(parts[3])
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				isPause, err := strconv.ParseBool(parts[4])
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				output = append(output, revoutil.Note{
-					Value:    value,
-					Duration: duration,
-					Channel:  channel,
-					Track:    track,
-					IsPause:  isPause,
-				})
-			}
-		}
+	notes, err := process.finish()
+	if err != nil {
+		process.stop()
+		return modification{}, err
+	}
+	output = append(output, notes...)
 
-		if isFinishing {
-			break
-		}
+	pool.put(path, args, process)
 
-		if currIndex < len(input) {
-			sendNote()
-		} else {
-			io.WriteString(stdin, "finish\n")
-			isFinishing = true
-		}
-	}
 	return modification{
 		path:   path,
 		args:   args,
 		input:  input,
 		output: output,
+	}, nil
+}
+
+// Returns the modification of the input by the modifier, taking it from the
+// cache under the key if possible.
+func newCachedModification(diskCache *cache.Cache, pool *modifierPool, key string, path string, args []string, input modificationInput) (modification, error) {
+	output, ok, err := diskCache.LoadModification(key)
+	if err != nil {
+		fmt.Println("Failed to load cached modification:", err)
 	}
+	if ok {
+		return modification{
+			path:   path,
+			args:   args,
+			input:  input,
+			output: output,
+		}, nil
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	modification, err := newModification(pool, path, args, input, &wg)
+
+	wg.Wait()
+
+	if err != nil {
+		return modification, err
+	}
+
+	if err := diskCache.StoreModification(key, modification.output); err != nil {
+		fmt.Println("Failed to store modification in cache:", err)
+	}
+
+	return modification, nil
 }
@@ -0,0 +1,16 @@
+package interpret
+
+import "revolution/protocol"
+
+// What a modifier is given to modify. The fields are exported to be part
+// of the cache key.
+type modificationInput struct {
+	// The notes to modify with their absolute starts.
+	Notes []protocol.Note
+	// The context of each note.
+	Contexts []protocol.Context
+	// The notes of the targeted channels and tracks within the window
+	// before and after the modified range.
+	Before []protocol.Note
+	After  []protocol.Note
+}
@@ -0,0 +1,146 @@
+package interpret
+
+import (
+	"os"
+	"revolution/protocol"
+	"strings"
+	"sync"
+)
+
+// Idle modifier processes by path and args, kept running so that they can
+// serve the next job without being started again.
+type modifierPool struct {
+	mu   sync.Mutex
+	idle map[string][]*componentProcess
+	// Keys used since the previous prune.
+	used map[string]bool
+	// Whether modifiers have the context capability.
+	hasContext map[componentKey]bool
+}
+
+func newModifierPool() *modifierPool {
+	return &modifierPool{
+		idle:       make(map[string][]*componentProcess),
+		used:       make(map[string]bool),
+		hasContext: make(map[componentKey]bool),
+	}
+}
+
+func modifierPoolKey(path string, args []string) string {
+	return path + "\x00" + strings.Join(args, "\x00")
+}
+
+// Returns an idle process of the modifier, or starts a new one if there is
+// none.
+func (p *modifierPool) get(path string, args []string) (*componentProcess, error) {
+	key := modifierPoolKey(path, args)
+
+	p.mu.Lock()
+	p.used[key] = true
+
+	for len(p.idle[key]) > 0 {
+		last := len(p.idle[key]) - 1
+		process := p.idle[key][last]
+		p.idle[key] = p.idle[key][:last]
+
+		select {
+		case <-process.exited:
+			// Exited while idle.
+			process.stop()
+		default:
+			p.mu.Unlock()
+			return process, nil
+		}
+	}
+
+	p.mu.Unlock()
+
+	return startComponentProcess(path, args)
+}
+
+// Resets the process and returns it to the pool if the modifier can serve
+// another job. Other processes are stopped.
+func (p *modifierPool) put(path string, args []string, process *componentProcess) {
+	if process.legacy || !process.hello.HasCapability(protocol.CapabilityReset) {
+		process.stop()
+		return
+	}
+
+	if err := process.send(protocol.Reset{Type: protocol.TypeReset}); err != nil {
+		process.stop()
+		return
+	}
+
+	key := modifierPoolKey(path, args)
+
+	p.mu.Lock()
+	p.idle[key] = append(p.idle[key], process)
+	p.mu.Unlock()
+}
+
+// Reports whether the modifier has the context capability, with which it may
+// place notes at explicit starts. The modifier is asked once, and the process
+// is kept for its next job.
+func (p *modifierPool) contextCapability(path string, args []string) bool {
+	var key componentKey
+
+	if stat, err := os.Stat(path); err == nil {
+		key = componentKey{path, stat.ModTime()}
+	}
+
+	p.mu.Lock()
+	hasContext, ok := p.hasContext[key]
+	p.mu.Unlock()
+
+	if ok {
+		return hasContext
+	}
+
+	process, err := p.get(path, args)
+	if err != nil {
+		// The job fails as well and leaves its notes unmodified.
+		return false
+	}
+
+	hasContext = process.hello.HasCapability(protocol.CapabilityContext)
+
+	p.put(path, args, process)
+
+	p.mu.Lock()
+	p.hasContext[key] = hasContext
+	p.mu.Unlock()
+
+	return hasContext
+}
+
+// Stops the idle processes of modifiers that have not been used since the
+// previous prune.
+func (p *modifierPool) prune() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, processes := range p.idle {
+		if p.used[key] {
+			continue
+		}
+		for _, process := range processes {
+			process.stop()
+		}
+		delete(p.idle, key)
+	}
+
+	p.used = make(map[string]bool)
+}
+
+// Stops all idle processes.
+func (p *modifierPool) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, processes := range p.idle {
+		for _, process := range processes {
+			process.stop()
+		}
+		delete(p.idle, key)
+	}
+}
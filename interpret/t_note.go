@@ -2,11 +2,18 @@ package interpret
 
 import "golang.org/x/exp/slices"
 
+// The velocity of notes that have not been accented.
+const defaultVelocity = 64
+
 type Note struct {
 	Value    int
 	Start    float64
 	Duration float64
+	Velocity uint8
 	IsPause  bool
+	// Tie marks the note as a continuation of the preceding note on the
+	// same channel and track.
+	Tie bool
 
 	Channel int
 	Track   int
@@ -33,13 +40,21 @@ func getFromTo(slice []Note, from float64, to float64) []Note {
 		i -= 1
 	}
 
-	slice = slice[i:j]
+	if i < 0 {
+		i = 0
+	}
+
+	// Copy to avoid trimming the notes of the underlying generation.
+	slice = slices.Clone(slice[i:j])
 
 	if len(slice) == 0 {
 		return slice
 	}
 
-	slice[0].Start = from
+	if slice[0].Start < from {
+		slice[0].Duration -= from - slice[0].Start
+		slice[0].Start = from
+	}
 
 	return slice
 }
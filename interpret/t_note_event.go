@@ -0,0 +1,8 @@
+package interpret
+
+// A note on or note off event of a note, at an absolute position in ticks.
+type noteEvent struct {
+	tick uint32
+	isOn bool
+	note Note
+}
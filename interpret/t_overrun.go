@@ -0,0 +1,23 @@
+package interpret
+
+import "fmt"
+
+// Determines what happens to a note that overruns the end of its item.
+type overrun string
+
+const (
+	// Shorten the note so that it ends with the item.
+	overrunClip overrun = "clip"
+	// Leave out the note entirely.
+	overrunDrop overrun = "drop"
+	// Let the note sound past the end of the item.
+	overrunKeep overrun = "keep"
+)
+
+func stringToOverrun(s string) (overrun, error) {
+	switch overrun(s) {
+	case overrunClip, overrunDrop, overrunKeep:
+		return overrun(s), nil
+	}
+	return "", fmt.Errorf("invalid overrun: %s", s)
+}
@@ -0,0 +1,13 @@
+package interpret
+
+import "fmt"
+
+// A component speaks another protocol version than expected.
+type versionError struct {
+	version  int
+	expected int
+}
+
+func (e versionError) Error() string {
+	return fmt.Sprintf("component speaks protocol version %d, expected %d", e.version, e.expected)
+}
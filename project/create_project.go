@@ -66,7 +66,7 @@ func CreateProject(name, template string) error {
 		/* Create .gitignore file */
 
 		path := filepath.Join(projDir, ".gitignore")
-		content := "/.extensions"
+		content := "/.extensions\n/.cache"
 
 		os.WriteFile(path, []byte(content), 0777)
 	}
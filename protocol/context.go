@@ -0,0 +1,20 @@
+package protocol
+
+// Context describes where in the composition a note is placed.
+type Context struct {
+	// Position of the note in whole notes from the start of the composition.
+	Position float64 `json:"position"`
+	// Bar the note starts in, counting from 0.
+	Bar int `json:"bar"`
+	// Position of the note within its bar in beats, counting from 0.
+	Beat float64 `json:"beat"`
+	// Root pitch class of the key, e.g. "C#".
+	Root string `json:"root"`
+	// Mode of the key.
+	Mode int `json:"mode"`
+	// Meter as numerator and denominator.
+	Numerator   int `json:"numerator"`
+	Denominator int `json:"denominator"`
+	// Tempo in beats per minute.
+	Tempo float64 `json:"tempo"`
+}
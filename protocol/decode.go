@@ -0,0 +1,30 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Decode unmarshals the line into v, which must be a message of the wanted
+// type. An error message from the component is returned as an error.
+func Decode(line []byte, wantedType string, v any) error {
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil {
+		return fmt.Errorf("invalid message: %s", line)
+	}
+
+	if header.Type == TypeError {
+		var message Error
+		if err := json.Unmarshal(line, &message); err != nil {
+			return err
+		}
+		return errors.New(message.Message)
+	}
+
+	if header.Type != wantedType {
+		return fmt.Errorf("unexpected message of type '%s', expected '%s'", header.Type, wantedType)
+	}
+
+	return json.Unmarshal(line, v)
+}
@@ -0,0 +1,8 @@
+package protocol
+
+import "golang.org/x/exp/slices"
+
+// HasCapability reports whether the hello announces the capability.
+func (h Hello) HasCapability(capability string) bool {
+	return slices.Contains(h.Capabilities, capability)
+}
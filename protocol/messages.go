@@ -0,0 +1,120 @@
+package protocol
+
+// Message types.
+const (
+	TypeHello     = "hello"
+	TypeGenerate  = "generate"
+	TypeGenerated = "generated"
+	TypeRange     = "range"
+	TypeStop      = "stop"
+	TypeDone      = "done"
+	TypeWindow    = "window"
+	TypeModify    = "modify"
+	TypeFinish    = "finish"
+	TypeModified  = "modified"
+	TypeReset     = "reset"
+	TypeError     = "error"
+)
+
+// Capabilities a component may announce in its hello message.
+const (
+	CapabilityGenerate = "generate"
+	CapabilityRange    = "range"
+	CapabilityContext  = "context"
+	CapabilityModify   = "modify"
+	CapabilityFinish   = "finish"
+	CapabilityReset    = "reset"
+)
+
+// Header is the part shared by all messages.
+type Header struct {
+	Type string `json:"type"`
+}
+
+// Hello is sent by the interpreter to start a session. The component
+// answers with a hello of its own announcing its version and capabilities.
+type Hello struct {
+	Type         string   `json:"type"`
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// Generate requests the note at an index from a generator. The context is
+// only sent to generators with the context capability.
+type Generate struct {
+	Type    string   `json:"type"`
+	Index   int      `json:"index"`
+	Context *Context `json:"context,omitempty"`
+}
+
+// Range requests the notes at count indices from a generator, beginning at
+// start and moving by step. The generator streams a generated message for
+// each index followed by a done message. The interpreter may send a stop
+// message to end the range early, after which the generator still sends
+// the done message.
+type Range struct {
+	Type  string `json:"type"`
+	Start int    `json:"start"`
+	Count int    `json:"count"`
+	Step  int    `json:"step"`
+}
+
+// Stop ends the current range.
+type Stop struct {
+	Type string `json:"type"`
+}
+
+// Done marks the end of a range.
+type Done struct {
+	Type string `json:"type"`
+}
+
+// Generated is the answer of a generator to a generate message, and is
+// sent for every index of a range.
+type Generated struct {
+	Type     string  `json:"type"`
+	Index    int     `json:"index"`
+	Degree   int     `json:"degree"`
+	Duration float64 `json:"duration"`
+}
+
+// Window passes the notes surrounding the modified range to a modifier
+// with the context capability. It is sent before the first modify message.
+type Window struct {
+	Type   string `json:"type"`
+	Before []Note `json:"before"`
+	After  []Note `json:"after"`
+}
+
+// Modify passes the next note to a modifier. The context is only sent to
+// modifiers with the context capability.
+type Modify struct {
+	Type    string   `json:"type"`
+	Note    Note     `json:"note"`
+	Context *Context `json:"context,omitempty"`
+}
+
+// Finish tells a modifier that there are no more notes.
+type Finish struct {
+	Type string `json:"type"`
+}
+
+// Reset tells a modifier with the reset capability to start a new job, as
+// if it had just been started. It is sent after the finish message of the
+// previous job, or after its last modify message if the modifier has no
+// finish capability. No answer is sent.
+type Reset struct {
+	Type string `json:"type"`
+}
+
+// Modified is the answer of a modifier to a modify or finish message.
+type Modified struct {
+	Type  string `json:"type"`
+	Notes []Note `json:"notes"`
+}
+
+// Error may be sent by a component instead of an answer.
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,13 @@
+package protocol
+
+type Note struct {
+	Value int `json:"value"`
+	// Start of the note in whole notes from the start of the composition.
+	// Notes returned by a modifier without a start are placed after the
+	// previous note of their channel and track.
+	Start    *float64 `json:"start,omitempty"`
+	Duration float64  `json:"duration"`
+	Channel  int      `json:"channel"`
+	Track    int      `json:"track"`
+	IsPause  bool     `json:"isPause"`
+}
@@ -0,0 +1,13 @@
+package protocol
+
+// The version of the protocol spoken by the interpreter. Components announce
+// the version they speak in their hello message.
+//
+// Components of version 2 take their arguments by name as --name=value.
+// Components of version 1 take the values alone, ordered by the name of
+// their parameter.
+const Version = 2
+
+// The last version of the protocol whose components take their arguments
+// by position.
+const PositionalVersion = 1